@@ -0,0 +1,42 @@
+package api
+
+import (
+	"effective-mobile/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+type AdminHandler struct {
+	quotaService services.QuotaServiceInterface
+}
+
+func NewAdminHandler(quotaService services.QuotaServiceInterface) *AdminHandler {
+	log.Debug().Msg("Initializing AdminHandler")
+	return &AdminHandler{quotaService: quotaService}
+}
+
+// GetQuota godoc
+// @Summary Остаток квот внешних сервисов
+// @Description Возвращает последние известные лимиты запросов agify, genderize и nationalize. Сервис с исчерпанной квотой не вызывается до её обновления
+// @Tags admin
+// @Produce json
+// @Success 200 {array} dtos.ProviderQuotaDto "Квоты внешних сервисов"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль admin"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/quota [get]
+func (h *AdminHandler) GetQuota(c *gin.Context) {
+	reqId := getRequestID(c)
+
+	quotas := h.quotaService.GetQuotas(c.Request.Context())
+
+	log.Info().
+		Str("request_id", reqId).
+		Int("providers_count", len(quotas)).
+		Msg("Provider quotas retrieved")
+
+	c.JSON(http.StatusOK, quotas)
+}
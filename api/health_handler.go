@@ -0,0 +1,60 @@
+package api
+
+import (
+	"effective-mobile/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+type HealthHandler struct {
+	healthService services.HealthServiceInterface
+}
+
+func NewHealthHandler(healthService services.HealthServiceInterface) *HealthHandler {
+	log.Debug().Msg("Initializing HealthHandler")
+	return &HealthHandler{healthService: healthService}
+}
+
+// Livez godoc
+// @Summary Проверка живости
+// @Description Отвечает ok, пока процесс способен обрабатывать запросы. Зависимости не проверяются, поэтому недоступность базы не приводит к перезапуску
+// @Tags health
+// @Produce json
+// @Success 200 {object} dtos.HealthDto "Процесс жив"
+// @Router /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	reqId := getRequestID(c)
+	log.Debug().
+		Str("request_id", reqId).
+		Msg("Liveness check requested")
+
+	c.JSON(http.StatusOK, h.healthService.Liveness(c.Request.Context()))
+}
+
+// Readyz godoc
+// @Summary Проверка готовности
+// @Description Проверяет доступность базы, отсутствие непримененных миграций и состояние внешних сервисов обогащения. Исчерпанная квота сервиса отображается как открытый circuit и не снимает готовность. Во время плавной остановки всегда возвращает 503
+// @Tags health
+// @Produce json
+// @Success 200 {object} dtos.ReadinessDto "Сервис готов принимать запросы"
+// @Failure 503 {object} dtos.ReadinessDto "Критическая зависимость недоступна или сервис останавливается"
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	reqId := getRequestID(c)
+
+	readiness, ready := h.healthService.Readiness(c.Request.Context())
+	if !ready {
+		log.Warn().
+			Str("request_id", reqId).
+			Bool("shutting_down", readiness.ShuttingDown).
+			Msg("Service is not ready")
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	log.Debug().
+		Str("request_id", reqId).
+		Msg("Service is ready")
+	c.JSON(http.StatusOK, readiness)
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"effective-mobile/internal/dtos"
 	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
+	"effective-mobile/internal/redact"
 	"effective-mobile/internal/services"
 	"errors"
 	"github.com/gin-gonic/gin"
@@ -31,9 +33,20 @@ func NewPersonHandler(personService services.PersonServiceInterface) *PersonHand
 // @Accept json
 // @Produce json
 // @Param person body dtos.CreatePersonDto true "Информация о человеке"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} dtos.PersonDto "Созданная запись о человеке"
-// @Failure 400 {object} map[string]string "Ошибка валидации запроса"
-// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 409 {object} dtos.ProblemDto "Запрос с этим ключом идемпотентности ещё обрабатывается"
+// @Failure 422 {object} dtos.ProblemDto "Ключ идемпотентности уже использован с другим телом запроса"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль editor"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Failure 502 {object} dtos.ProblemDto "Внешние сервисы обогащения вернули ошибку"
+// @Failure 503 {object} dtos.ProblemDto "Исчерпана квота внешнего сервиса обогащения"
+// @Failure 504 {object} dtos.ProblemDto "Внешние сервисы обогащения не ответили вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /persons [post]
 func (h *PersonHandler) CreatePerson(c *gin.Context) {
 	log.Info().Msg("CreatePerson handler started")
@@ -44,16 +57,16 @@ func (h *PersonHandler) CreatePerson(c *gin.Context) {
 		log.Error().
 			Err(err).
 			Str("request_id", reqId).
-			Str("payload", c.Request.URL.String()).
+			Func(redact.SensitiveStr("payload", c.Request.URL.String())).
 			Msg(custom_errors.ErrBindJsonBody.Message)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to create person: " + err.Error()})
+		problem.AbortBind(c, err)
 		return
 	}
 
 	log.Debug().
 		Str("request_id", reqId).
-		Str("name", createPersonDto.Name).
-		Str("surname", createPersonDto.Surname).
+		Func(redact.Str(redact.FieldName, createPersonDto.Name)).
+		Func(redact.Str(redact.FieldSurname, createPersonDto.Surname)).
 		Msg("Attempting to create person")
 
 	personDto, err := h.personService.CreatePerson(c.Request.Context(), createPersonDto)
@@ -64,7 +77,7 @@ func (h *PersonHandler) CreatePerson(c *gin.Context) {
 			Str("request_id", reqId).
 			Str("error_type", "user_error").
 			Msg("User error when creating person")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to create person: " + userErr.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -73,7 +86,7 @@ func (h *PersonHandler) CreatePerson(c *gin.Context) {
 			Err(err).
 			Str("request_id", reqId).
 			Msg("Server error when creating person")
-		c.JSON(http.StatusInternalServerError, gin.H{"CreatePerson error": err.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -87,14 +100,20 @@ func (h *PersonHandler) CreatePerson(c *gin.Context) {
 
 // UpdatePerson godoc
 // @Summary Обновление данных о человеке
-// @Description Обновляет существующую запись о человеке на основе переданных данных
+// @Description Обновляет существующую запись о человеке на основе переданных данных. Значения проверяются так же, как при создании: возраст от 0 до 150, пол male или female, страна - код ISO 3166-1 alpha-2 (регистр не важен, сохраняется в верхнем регистре)
 // @Tags persons
 // @Accept json
 // @Produce json
 // @Param person body dtos.PersonDto true "Информация о человеке для обновления"
 // @Success 200 {object} dtos.PersonDto "Обновленная запись о человеке"
-// @Failure 400 {object} map[string]string "Ошибка валидации запроса"
-// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 404 {object} dtos.ProblemDto "Человек не найден"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль editor"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /persons [put]
 func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 	log.Info().Msg("UpdatePerson handler started")
@@ -105,9 +124,9 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 		log.Error().
 			Err(err).
 			Str("request_id", reqId).
-			Str("payload", c.Request.URL.String()).
+			Func(redact.SensitiveStr("payload", c.Request.URL.String())).
 			Msg(custom_errors.ErrBindJsonBody.Message)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to update person: " + err.Error()})
+		problem.AbortBind(c, err)
 		return
 	}
 
@@ -125,7 +144,7 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 			Str("person_id", updatePersonDto.Id.String()).
 			Str("error_type", "user_error").
 			Msg("User error when updating person")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to update person: " + userErr.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -135,7 +154,7 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 			Str("request_id", reqId).
 			Str("person_id", updatePersonDto.Id.String()).
 			Msg("Server error when updating person")
-		c.JSON(http.StatusInternalServerError, gin.H{"UpdatePerson error": err.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -154,8 +173,14 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ID человека" format(uuid)
 // @Success 200 {object} map[string]string "Сообщение об успешном удалении"
-// @Failure 400 {object} map[string]string "Ошибка валидации запроса"
-// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 404 {object} dtos.ProblemDto "Человек не найден"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль admin"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /persons/{id} [delete]
 func (h *PersonHandler) DeletePerson(c *gin.Context, personId pgtype.UUID) {
 	log.Info().Msg("DeletePerson handler started")
@@ -175,7 +200,7 @@ func (h *PersonHandler) DeletePerson(c *gin.Context, personId pgtype.UUID) {
 			Str("person_id", personId.String()).
 			Str("error_type", "user_error").
 			Msg("User error when deleting person")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to delete person: " + userErr.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -185,7 +210,7 @@ func (h *PersonHandler) DeletePerson(c *gin.Context, personId pgtype.UUID) {
 			Str("request_id", reqId).
 			Str("person_id", personId.String()).
 			Msg("Server error when deleting person")
-		c.JSON(http.StatusInternalServerError, gin.H{"DeletePerson error": err.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -205,8 +230,13 @@ func (h *PersonHandler) DeletePerson(c *gin.Context, personId pgtype.UUID) {
 // @Produce json
 // @Param filter body dtos.GetPersonDto true "Параметры фильтрации"
 // @Success 200 {array} dtos.PersonDto "Список людей"
-// @Failure 400 {object} map[string]string "Ошибка валидации запроса"
-// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль reader"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /persons [get]
 func (h *PersonHandler) GetPersons(c *gin.Context) {
 	log.Info().Msg("GetPersons handler started")
@@ -217,9 +247,9 @@ func (h *PersonHandler) GetPersons(c *gin.Context) {
 		log.Error().
 			Err(err).
 			Str("request_id", reqId).
-			Str("payload", c.Request.URL.String()).
+			Func(redact.SensitiveStr("payload", c.Request.URL.String())).
 			Msg(custom_errors.ErrBindJsonBody.Message)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to get persons: " + err.Error()})
+		problem.AbortBind(c, err)
 		return
 	}
 
@@ -236,7 +266,7 @@ func (h *PersonHandler) GetPersons(c *gin.Context) {
 			Str("request_id", reqId).
 			Str("error_type", "user_error").
 			Msg("User error when getting persons")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to get persons: " + userErr.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -245,7 +275,7 @@ func (h *PersonHandler) GetPersons(c *gin.Context) {
 			Err(err).
 			Str("request_id", reqId).
 			Msg("Server error when getting persons")
-		c.JSON(http.StatusInternalServerError, gin.H{"GetPersons error": err.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -264,8 +294,14 @@ func (h *PersonHandler) GetPersons(c *gin.Context) {
 // @Produce json
 // @Param id path string true "ID человека" format(uuid)
 // @Success 200 {object} dtos.PersonDto "Информация о человеке"
-// @Failure 400 {object} map[string]string "Ошибка валидации запроса"
-// @Failure 500 {object} map[string]string "Ошибка сервера"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 404 {object} dtos.ProblemDto "Человек не найден"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль reader"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /persons/{id} [get]
 func (h *PersonHandler) GetPersonById(c *gin.Context, personId pgtype.UUID) {
 	log.Info().Msg("GetPersonById handler started")
@@ -285,7 +321,7 @@ func (h *PersonHandler) GetPersonById(c *gin.Context, personId pgtype.UUID) {
 			Str("person_id", personId.String()).
 			Str("error_type", "user_error").
 			Msg("User error when getting person by ID")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format to get person by id: " + userErr.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -295,7 +331,7 @@ func (h *PersonHandler) GetPersonById(c *gin.Context, personId pgtype.UUID) {
 			Str("request_id", reqId).
 			Str("person_id", personId.String()).
 			Msg("Server error when getting person by ID")
-		c.JSON(http.StatusInternalServerError, gin.H{"GetPersonById error": err.Error()})
+		problem.Abort(c, err)
 		return
 	}
 
@@ -307,6 +343,291 @@ func (h *PersonHandler) GetPersonById(c *gin.Context, personId pgtype.UUID) {
 	c.JSON(http.StatusOK, personDto)
 }
 
+// GetEnrichmentResults godoc
+// @Summary Получение истории обогащения человека
+// @Description Возвращает ответы всех источников, рассмотренных при обогащении возраста, пола и национальности, с отметкой выбранного
+// @Tags persons
+// @Produce json
+// @Param id path string true "ID человека" format(uuid)
+// @Success 200 {array} dtos.EnrichmentProviderResultDto "Ответы источников"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 404 {object} dtos.ProblemDto "Человек не найден"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль reader"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /persons/{id}/enrichment-results [get]
+func (h *PersonHandler) GetEnrichmentResults(c *gin.Context, personId pgtype.UUID) {
+	log.Info().Msg("GetEnrichmentResults handler started")
+	reqId := getRequestID(c)
+
+	log.Debug().
+		Str("request_id", reqId).
+		Str("person_id", personId.String()).
+		Msg("Attempting to get enrichment results")
+
+	results, err := h.personService.GetEnrichmentResults(c.Request.Context(), personId)
+	var userErr *custom_errors.UserError
+	if errors.As(err, &userErr) {
+		log.Warn().
+			Err(err).
+			Str("request_id", reqId).
+			Str("person_id", personId.String()).
+			Str("error_type", "user_error").
+			Msg("User error when getting enrichment results")
+		problem.Abort(c, err)
+		return
+	}
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("request_id", reqId).
+			Str("person_id", personId.String()).
+			Msg("Server error when getting enrichment results")
+		problem.Abort(c, err)
+		return
+	}
+
+	log.Info().
+		Str("request_id", reqId).
+		Str("person_id", personId.String()).
+		Int("results_count", len(results)).
+		Msg("Enrichment results retrieved successfully")
+
+	c.JSON(http.StatusOK, results)
+}
+
+// EnrichPerson godoc
+// @Summary Повторное обогащение данных о человеке
+// @Description Повторно определяет возраст, пол и национальность человека. Поля, изменённые вручную через UpdatePerson, не перезаписываются
+// @Tags persons
+// @Produce json
+// @Param id path string true "ID человека" format(uuid)
+// @Success 200 {object} dtos.EnrichmentResultDto "Результат повторного обогащения"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 404 {object} dtos.ProblemDto "Человек не найден"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль editor"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Failure 502 {object} dtos.ProblemDto "Внешние сервисы обогащения вернули ошибку"
+// @Failure 503 {object} dtos.ProblemDto "Исчерпана квота внешнего сервиса обогащения"
+// @Failure 504 {object} dtos.ProblemDto "Внешние сервисы обогащения не ответили вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /persons/{id}/enrich [post]
+func (h *PersonHandler) EnrichPerson(c *gin.Context, personId pgtype.UUID) {
+	log.Info().Msg("EnrichPerson handler started")
+	reqId := getRequestID(c)
+
+	log.Debug().
+		Str("request_id", reqId).
+		Str("person_id", personId.String()).
+		Msg("Attempting to re-enrich person")
+
+	result, err := h.personService.EnrichPerson(c.Request.Context(), personId)
+	var userErr *custom_errors.UserError
+	if errors.As(err, &userErr) {
+		log.Warn().
+			Err(err).
+			Str("request_id", reqId).
+			Str("person_id", personId.String()).
+			Str("error_type", "user_error").
+			Msg("User error when re-enriching person")
+		problem.Abort(c, err)
+		return
+	}
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("request_id", reqId).
+			Str("person_id", personId.String()).
+			Msg("Server error when re-enriching person")
+		problem.Abort(c, err)
+		return
+	}
+
+	log.Info().
+		Str("request_id", reqId).
+		Str("person_id", personId.String()).
+		Int("changes_count", len(result.Changes)).
+		Msg("Person re-enriched successfully")
+
+	c.JSON(http.StatusOK, result)
+}
+
+// EnrichPersons godoc
+// @Summary Повторное обогащение данных о нескольких людях
+// @Description Повторно определяет возраст, пол и национальность людей, подходящих под фильтры. Поля, изменённые вручную, не перезаписываются. За один запрос обрабатывается не больше 20 человек (и не больше limit), число необработанных возвращается в skipped_count
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param filter body dtos.GetPersonDto true "Параметры фильтрации"
+// @Success 200 {object} dtos.EnrichmentBatchDto "Результаты повторного обогащения"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль admin"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Failure 502 {object} dtos.ProblemDto "Внешние сервисы обогащения вернули ошибку"
+// @Failure 503 {object} dtos.ProblemDto "Исчерпана квота внешнего сервиса обогащения"
+// @Failure 504 {object} dtos.ProblemDto "Внешние сервисы обогащения не ответили вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /persons/enrich [post]
+func (h *PersonHandler) EnrichPersons(c *gin.Context) {
+	log.Info().Msg("EnrichPersons handler started")
+	reqId := getRequestID(c)
+
+	var getPersonsDto dtos.GetPersonDto
+	if err := c.ShouldBindJSON(&getPersonsDto); err != nil {
+		log.Error().
+			Err(err).
+			Str("request_id", reqId).
+			Func(redact.SensitiveStr("payload", c.Request.URL.String())).
+			Msg(custom_errors.ErrBindJsonBody.Message)
+		problem.AbortBind(c, err)
+		return
+	}
+
+	batch, err := h.personService.EnrichPersons(c.Request.Context(), getPersonsDto)
+	var userErr *custom_errors.UserError
+	if errors.As(err, &userErr) {
+		log.Warn().
+			Err(err).
+			Str("request_id", reqId).
+			Str("error_type", "user_error").
+			Msg("User error when re-enriching persons")
+		problem.Abort(c, err)
+		return
+	}
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("request_id", reqId).
+			Msg("Server error when re-enriching persons")
+		problem.Abort(c, err)
+		return
+	}
+
+	log.Info().
+		Str("request_id", reqId).
+		Int("processed_count", len(batch.Results)).
+		Int64("skipped_count", batch.SkippedCount).
+		Msg("Persons re-enriched successfully")
+
+	// Failures of single persons are described like the errors of whole requests, the raw
+	// error was logged by the service.
+	for i := range batch.Results {
+		if batch.Results[i].Err == nil {
+			continue
+		}
+		failure := problem.New(c, batch.Results[i].Err)
+		batch.Results[i].Error = &failure.Detail
+		batch.Results[i].ErrorCode = &failure.Code
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// PreviewEnrichment godoc
+// @Summary Предварительное обогащение данных о человеке
+// @Description Определяет возраст, пол и национальность по имени так же, как при создании человека, но ничего не сохраняет в БД
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param person body dtos.CreatePersonDto true "Информация о человеке"
+// @Success 200 {object} dtos.EnrichmentPreviewDto "Предполагаемые атрибуты с вероятностями"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль editor"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Failure 502 {object} dtos.ProblemDto "Внешние сервисы обогащения вернули ошибку"
+// @Failure 503 {object} dtos.ProblemDto "Исчерпана квота внешнего сервиса обогащения"
+// @Failure 504 {object} dtos.ProblemDto "Внешние сервисы обогащения не ответили вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /enrich [post]
+func (h *PersonHandler) PreviewEnrichment(c *gin.Context) {
+	log.Info().Msg("PreviewEnrichment handler started")
+	reqId := getRequestID(c)
+
+	var createPersonDto dtos.CreatePersonDto
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&createPersonDto)
+	} else {
+		err = c.ShouldBindJSON(&createPersonDto)
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("request_id", reqId).
+			Func(redact.SensitiveStr("payload", c.Request.URL.String())).
+			Msg(custom_errors.ErrBindJsonBody.Message)
+		problem.AbortBind(c, err)
+		return
+	}
+
+	previewDto, err := h.personService.PreviewEnrichment(c.Request.Context(), createPersonDto)
+	var userErr *custom_errors.UserError
+	if errors.As(err, &userErr) {
+		log.Warn().
+			Err(err).
+			Str("request_id", reqId).
+			Str("error_type", "user_error").
+			Msg("User error when previewing enrichment")
+		problem.Abort(c, err)
+		return
+	}
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("request_id", reqId).
+			Msg("Server error when previewing enrichment")
+		problem.Abort(c, err)
+		return
+	}
+
+	log.Info().
+		Str("request_id", reqId).
+		Msg("Enrichment previewed successfully")
+
+	c.JSON(http.StatusOK, previewDto)
+}
+
+// PreviewEnrichmentByQuery godoc
+// @Summary Предварительное обогащение данных о человеке по параметрам запроса
+// @Description Определяет возраст, пол и национальность по имени так же, как при создании человека, но ничего не сохраняет в БД
+// @Tags enrichment
+// @Produce json
+// @Param name query string true "Имя"
+// @Param surname query string false "Фамилия"
+// @Param patronymic query string false "Отчество"
+// @Param country_hint query string false "Код страны ISO 3166-1 alpha-2 для уточнения возраста и пола"
+// @Success 200 {object} dtos.EnrichmentPreviewDto "Предполагаемые атрибуты с вероятностями"
+// @Failure 400 {object} dtos.ProblemDto "Ошибка валидации запроса, ошибки полей в errors"
+// @Failure 401 {object} dtos.ProblemDto "Не переданы или неверны учётные данные"
+// @Failure 403 {object} dtos.ProblemDto "Недостаточно прав, требуется роль editor"
+// @Failure 429 {object} dtos.ProblemDto "Превышен лимит запросов, время ожидания в заголовке Retry-After"
+// @Failure 500 {object} dtos.ProblemDto "Ошибка сервера"
+// @Failure 502 {object} dtos.ProblemDto "Внешние сервисы обогащения вернули ошибку"
+// @Failure 503 {object} dtos.ProblemDto "Исчерпана квота внешнего сервиса обогащения"
+// @Failure 504 {object} dtos.ProblemDto "Внешние сервисы обогащения не ответили вовремя"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /enrich [get]
+func (h *PersonHandler) PreviewEnrichmentByQuery(c *gin.Context) {
+	h.PreviewEnrichment(c)
+}
+
 func getRequestID(c *gin.Context) string {
 	reqID, exists := c.Get("RequestID")
 	if !exists {
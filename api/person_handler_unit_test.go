@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -56,6 +60,38 @@ func (m *MockPersonService) GetPersonById(ctx context.Context, personId pgtype.U
 	return args.Get(0).(*dtos.PersonDto), args.Error(1)
 }
 
+func (m *MockPersonService) EnrichPerson(ctx context.Context, personId pgtype.UUID) (*dtos.EnrichmentResultDto, error) {
+	args := m.Called(ctx, personId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.EnrichmentResultDto), args.Error(1)
+}
+
+func (m *MockPersonService) EnrichPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (*dtos.EnrichmentBatchDto, error) {
+	args := m.Called(ctx, getPersonDto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.EnrichmentBatchDto), args.Error(1)
+}
+
+func (m *MockPersonService) GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]dtos.EnrichmentProviderResultDto, error) {
+	args := m.Called(ctx, personId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.EnrichmentProviderResultDto), args.Error(1)
+}
+
+func (m *MockPersonService) PreviewEnrichment(ctx context.Context, personDto dtos.CreatePersonDto) (*dtos.EnrichmentPreviewDto, error) {
+	args := m.Called(ctx, personDto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.EnrichmentPreviewDto), args.Error(1)
+}
+
 func TestCreatePerson(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -221,3 +257,191 @@ func TestGetPersonById(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Equal(t, id, response.Id)
 }
+
+func TestEnrichPerson(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := new(MockPersonService)
+	handler := NewPersonHandler(mockService)
+
+	idBytes := uuid.New()
+	id := pgtype.UUID{Bytes: idBytes, Valid: true}
+
+	mockService.On("EnrichPerson", mock.Anything, id).Return(&dtos.EnrichmentResultDto{
+		Id: id,
+		Changes: []dtos.EnrichmentChangeDto{
+			{Field: "age", OldValue: "20", NewValue: "44"},
+		},
+		SkippedFields: []string{"gender"},
+	}, nil).Once()
+
+	router.POST("/persons/"+id.String()+"/enrich", func(c *gin.Context) {
+		handler.EnrichPerson(c, id)
+	})
+
+	req, _ := http.NewRequest("POST", "/persons/"+id.String()+"/enrich", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	var response dtos.EnrichmentResultDto
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, id, response.Id)
+	assert.Equal(t, "44", response.Changes[0].NewValue)
+	assert.Equal(t, []string{"gender"}, response.SkippedFields)
+}
+
+func TestEnrichPersons(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := new(MockPersonService)
+	handler := NewPersonHandler(mockService)
+
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	failure := fmt.Errorf("update failed: %w", &custom_errors.InternalError{Message: "password authentication failed for user app"})
+
+	mockService.On("EnrichPersons", mock.Anything, mock.Anything).Return(&dtos.EnrichmentBatchDto{
+		Results: []dtos.EnrichmentResultDto{{
+			Id:            id,
+			Changes:       []dtos.EnrichmentChangeDto{},
+			SkippedFields: []string{},
+			Err:           failure,
+		}},
+	}, nil).Once()
+
+	router.POST("/persons/enrich", handler.EnrichPersons)
+
+	req, _ := http.NewRequest("POST", "/persons/enrich", bytes.NewBufferString(`{}`))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var response dtos.EnrichmentBatchDto
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if assert.Len(t, response.Results, 1) && assert.NotNil(t, response.Results[0].ErrorCode) {
+		assert.Equal(t, problem.CodeInternal, *response.Results[0].ErrorCode)
+		assert.NotEmpty(t, *response.Results[0].Error)
+	}
+}
+
+func TestPreviewEnrichment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := new(MockPersonService)
+	handler := NewPersonHandler(mockService)
+
+	mockService.On("PreviewEnrichment", mock.Anything, dtos.CreatePersonDto{Name: "Anna"}).Return(&dtos.EnrichmentPreviewDto{
+		Name:   "Anna",
+		Age:    dtos.AgeEstimateDto{Value: 40, Count: 100, Provider: "agify"},
+		Gender: dtos.GenderEstimateDto{Value: "female", Probability: 0.98, Count: 100, Provider: "genderize"},
+	}, nil).Twice()
+
+	router.GET("/enrich", handler.PreviewEnrichmentByQuery)
+	router.POST("/enrich", handler.PreviewEnrichment)
+
+	t.Run("PreviewEnrichment by query", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/enrich?name=Anna", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response dtos.EnrichmentPreviewDto
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "female", response.Gender.Value)
+		assert.Equal(t, 0.98, response.Gender.Probability)
+	})
+
+	t.Run("PreviewEnrichment by body", func(t *testing.T) {
+		jsonData, _ := json.Marshal(dtos.CreatePersonDto{Name: "Anna"})
+		req, _ := http.NewRequest("POST", "/enrich", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response dtos.EnrichmentPreviewDto
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, uint32(40), response.Age.Value)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPersonHandlerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockService := new(MockPersonService)
+	handler := NewPersonHandler(mockService)
+
+	idBytes := uuid.New()
+	id := pgtype.UUID{Bytes: idBytes, Valid: true}
+
+	router.GET("/persons/"+id.String(), func(c *gin.Context) {
+		handler.GetPersonById(c, id)
+	})
+	router.PUT("/persons", handler.UpdatePerson)
+	router.POST("/persons", handler.CreatePerson)
+
+	t.Run("Missing person", func(t *testing.T) {
+		mockService.On("GetPersonById", mock.Anything, id).Return(nil, custom_errors.ErrPersonNotFound).Once()
+
+		req, _ := http.NewRequest("GET", "/persons/"+id.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+		var response dtos.ProblemDto
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, problem.CodePersonNotFound, response.Code)
+	})
+
+	t.Run("No fields to update", func(t *testing.T) {
+		mockService.On("UpdatePerson", mock.Anything, dtos.PersonDto{Id: id}).Return(nil, custom_errors.ErrNoFieldsToUpdate).Once()
+
+		jsonData, _ := json.Marshal(dtos.PersonDto{Id: id})
+		req, _ := http.NewRequest("PUT", "/persons", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response dtos.ProblemDto
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, problem.CodeNoFieldsToUpdate, response.Code)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		createPersonDto := dtos.CreatePersonDto{Name: "Anna"}
+		mockService.On("CreatePerson", mock.Anything, createPersonDto).
+			Return(nil, &custom_errors.InternalError{Message: "failed to create person", Err: errors.New("relation persons does not exist")}).Once()
+
+		jsonData, _ := json.Marshal(createPersonDto)
+		req, _ := http.NewRequest("POST", "/persons", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "relation")
+
+		var response dtos.ProblemDto
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, problem.CodeInternal, response.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,62 @@
+// Command fakeenrich serves local stand-ins of the agify, genderize and nationalize APIs.
+package main
+
+import (
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/fakeenrich"
+	"flag"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	flags := flag.NewFlagSet("fakeenrich", flag.ExitOnError)
+	addr := flags.String("addr", ":8090", "Address to listen on")
+	datasetPath := flags.String("dataset", "", "Path of the name dataset CSV, the bundled one when empty")
+	latency := flags.Duration("latency", 0, "Delay added to every response")
+	errorRate := flags.Float64("error-rate", 0, "Share of requests, from 0 to 1, answered with -error-status")
+	errorStatus := flags.Int("error-status", http.StatusInternalServerError, "Status code of injected errors")
+	limit := flags.Int("limit", 0, "Names allowed per -limit-window for each API, 0 for no limit")
+	limitWindow := flags.Duration("limit-window", 24*time.Hour, "Rate limit window")
+	apiKey := flags.String("api-key", "", "API key the clients must pass, none when empty")
+	debug := flags.Bool("debug", false, "Log every request")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		log.Fatal().Err(err).Msg("Failed to parse fakeenrich flags")
+	}
+
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if *debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+
+	dataset, err := enrichment.LoadDataset(*datasetPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load name dataset")
+	}
+
+	handler := fakeenrich.NewMux(fakeenrich.Options{
+		Dataset:     dataset,
+		Latency:     *latency,
+		ErrorRate:   *errorRate,
+		ErrorStatus: *errorStatus,
+		Limit:       *limit,
+		LimitWindow: *limitWindow,
+		ApiKey:      *apiKey,
+	})
+
+	log.Info().
+		Str("addr", *addr).
+		Str("age_url", "http://localhost"+*addr+"/agify/?name=").
+		Str("gender_url", "http://localhost"+*addr+"/genderize/?name=").
+		Str("country_url", "http://localhost"+*addr+"/nationalize/?name=").
+		Msg("Fake enrichment APIs listening")
+
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		log.Fatal().Err(err).Msg("Fake enrichment server failed")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/internal/config"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/services"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func runApiKey(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		log.Fatal().Msg("Usage: apikey create <name> [roles] | list | revoke <name>")
+	}
+
+	ctx := context.Background()
+
+	dbpool := connectDatabase(ctx, cfg.Database)
+	defer dbpool.Close()
+
+	authService := services.NewAuthService(drivers.NewApiKeyDriver(dbpool), nil)
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 && len(args) != 3 {
+			log.Fatal().Msg("Usage: apikey create <name> [roles]")
+		}
+		// Roles are comma separated, a key without them can only read persons.
+		roles := []string{models.RoleReader}
+		if len(args) == 3 {
+			roles = strings.Split(args[2], ",")
+		}
+		key, apiKey, err := authService.CreateApiKey(ctx, args[1], roles)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to create api key")
+		}
+		log.Info().
+			Str("api_key_name", apiKey.Name).
+			Strs("roles", apiKey.Roles).
+			Msg("Api key created, it is shown only once")
+		fmt.Println(key)
+	case "list":
+		apiKeys, err := authService.ListApiKeys(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to list api keys")
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "NAME\tPREFIX\tROLES\tCREATED AT\tREVOKED AT")
+		for _, apiKey := range apiKeys {
+			revokedAt := "-"
+			if apiKey.IsRevoked() {
+				revokedAt = apiKey.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+				apiKey.Name, apiKey.Prefix, strings.Join(apiKey.Roles, ","), apiKey.CreatedAt.Format(time.RFC3339), revokedAt)
+		}
+		writer.Flush()
+	case "revoke":
+		if len(args) != 2 {
+			log.Fatal().Msg("Usage: apikey revoke <name>")
+		}
+		if err := authService.RevokeApiKey(ctx, args[1]); err != nil {
+			log.Fatal().Err(err).Msg("Failed to revoke api key")
+		}
+		log.Info().Str("api_key_name", args[1]).Msg("Api key revoked")
+	default:
+		log.Fatal().Str("command", args[0]).Msg("Unknown apikey command. Available commands: create, list, revoke")
+	}
+}
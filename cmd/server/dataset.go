@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/internal/config"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/enrichment"
+	"flag"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+func runBuildDataset(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("build-dataset", flag.ExitOnError)
+	out := flags.String("out", "names.csv", "Path of the dataset file to write, \"-\" for stdout")
+	if err := flags.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Failed to parse build-dataset flags")
+	}
+
+	ctx := context.Background()
+
+	dbpool := connectDatabase(ctx, cfg.Database)
+	defer dbpool.Close()
+
+	statistics, err := drivers.NewPersonDriver(dbpool).GetNameStatistics(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to collect name statistics")
+	}
+
+	file := os.Stdout
+	if *out != "-" {
+		file, err = os.Create(*out)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", *out).Msg("Failed to create dataset file")
+		}
+		defer file.Close()
+	}
+
+	if err = enrichment.WriteDataset(file, statistics); err != nil {
+		log.Fatal().Err(err).Str("path", *out).Msg("Failed to write dataset")
+	}
+
+	log.Info().
+		Str("path", *out).
+		Int("names_count", len(statistics)).
+		Msg("Name dataset written")
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"effective-mobile/api"
 	_ "effective-mobile/docs"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/config"
 	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/metrics"
 	"effective-mobile/internal/middlerwares"
+	"effective-mobile/internal/models"
 	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
+	"effective-mobile/internal/redact"
 	"effective-mobile/internal/services"
+	"effective-mobile/internal/tracing"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	swaggerFiles "github.com/swaggo/files"
@@ -20,16 +27,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 )
 
-func setupLogging() {
-	logLevelStr := strings.ToLower(os.Getenv("LOG_LEVEL"))
+func setupLogging(cfg *config.Config) {
 	var logLevel zerolog.Level
 
-	switch logLevelStr {
+	switch cfg.Logging.Level {
 	case "trace":
 		logLevel = zerolog.TraceLevel
 	case "debug":
@@ -51,7 +56,7 @@ func setupLogging() {
 	zerolog.SetGlobalLevel(logLevel)
 	log.Info().Msgf("Log level set to %s", logLevel.String())
 
-	if os.Getenv("ENV") != "production" {
+	if !cfg.IsProduction() {
 		log.Logger = log.Output(zerolog.ConsoleWriter{
 			Out:        os.Stderr,
 			TimeFormat: time.RFC3339,
@@ -61,6 +66,15 @@ func setupLogging() {
 		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	}
 
+	redact.Configure(redact.Options{
+		Policies:  cfg.Logging.Redaction,
+		Salt:      cfg.Logging.RedactionSalt,
+		Sensitive: cfg.Logging.DebugSensitive,
+	})
+	if cfg.Logging.DebugSensitive {
+		log.Warn().Msg("Sensitive debug logging is enabled, logs may contain personal data")
+	}
+
 	log.Debug().Msg("Logging system initialized")
 }
 
@@ -68,6 +82,9 @@ func init() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	// log.Ctx falls back to the global logger for contexts without a request logger, such as
+	// the startup and the reenrich command.
+	zerolog.DefaultContextLogger = &log.Logger
 }
 
 // @title Person API
@@ -76,115 +93,174 @@ func init() {
 // @termsOfService http://swagger.io/terms/
 // @host localhost:8080
 // @schemes http https
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Статический API-ключ, выпускается командой apikey create
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT в формате "Bearer <токен>"
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Error().Err(err).Msg(custom_errors.ErrEnvLoading.Message)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
-	setupLogging()
+	setupLogging(cfg)
 
-	log.Info().Msg("Starting the application")
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
 
-	connString := os.Getenv("DB_CONNECTION_STRING")
-	if connString == "" {
-		log.Fatal().Msg("Database connection string not set")
+	switch command {
+	case "serve":
+		runServer(cfg)
+	case "reenrich":
+		runReenrich(cfg, os.Args[2:])
+	case "build-dataset":
+		runBuildDataset(cfg, os.Args[2:])
+	case "migrate":
+		runMigrate(cfg, os.Args[2:])
+	case "apikey":
+		runApiKey(cfg, os.Args[2:])
+	default:
+		log.Fatal().Str("command", command).Msg("Unknown command. Available commands: serve, reenrich, build-dataset, migrate, apikey")
 	}
+}
+
+func runServer(cfg *config.Config) {
+	log.Info().Msg("Starting the application")
 
 	ctx := context.Background()
 
-	log.Debug().Msg("Connecting to database")
-	dbpool, err := pgxpool.New(ctx, connString)
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(err).Msg(custom_errors.ErrCreatePool.Message)
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
 	}
-	log.Info().Msg("Connected to database successfully")
+
+	dbpool := connectDatabase(ctx, cfg.Database)
 	defer dbpool.Close()
 
+	if cfg.Database.AutoMigrate {
+		autoMigrate(ctx, dbpool)
+	}
+
 	log.Debug().Msg("Initializing application components")
-	personDriver := drivers.NewPersonDriver(dbpool)
-	personService := services.NewPersonService(personDriver)
+	personDriver := drivers.NewInstrumentedPersonDriver(drivers.NewPersonDriver(dbpool))
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(dbpool))
+	quotaTracker := enrichment.NewQuotaTracker()
+	personService := services.NewTracedPersonService(services.NewPersonService(personDriver, newEnricher(cfg.Enrichment, quotaTracker)))
 	personHandler := api.NewPersonHandler(personService)
+	adminHandler := api.NewAdminHandler(services.NewQuotaService(quotaTracker))
+	idempotencyDriver := drivers.NewIdempotencyDriver(dbpool)
+	idempotencyService := services.NewIdempotencyService(idempotencyDriver, cfg.Idempotency.Ttl)
+
+	migrator, err := drivers.NewMigrator(dbpool)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize migrations")
+	}
+	defer migrator.Close()
+	healthService := services.NewHealthService(drivers.NewHealthDriver(dbpool, migrator), quotaTracker)
+	healthHandler := api.NewHealthHandler(healthService)
+	authService := newAuthService(cfg.Auth, dbpool)
+	if cfg.Auth.Enabled {
+		requireCredentials(ctx, cfg.Auth, authService)
+	}
+	rateLimitService := newRateLimitService(cfg.RateLimit, dbpool)
 
-	if os.Getenv("ENV") == "production" {
+	purgeCtx, stopPurge := context.WithCancel(ctx)
+	defer stopPurge()
+	go purgeExpiredIdempotencyKeys(purgeCtx, idempotencyService)
+	go purgeIdleRateLimitBuckets(purgeCtx, rateLimitService)
+
+	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 		log.Debug().Msg("Gin set to release mode")
 	} else {
 		log.Debug().Msg("Gin running in debug mode")
 	}
 
-	router := gin.Default()
+	// gin.New, not gin.Default: requests are logged by LoggingMiddleware and panics answered by
+	// problem.Recovery, the gin logger and recovery would duplicate both.
+	router := gin.New()
 
-	router.Use(gin.Recovery())
+	router.Use(gin.CustomRecovery(problem.Recovery))
+	router.Use(middlerwares.TracingMiddleware(cfg.Tracing.ServiceName))
 	router.Use(middlerwares.RequestIdMiddleware())
 	router.Use(middlerwares.LoggingMiddleware())
+	router.Use(middlerwares.MetricsMiddleware())
 
 	log.Debug().Msg("Configuring API routes")
 
-	router.POST("/persons", personHandler.CreatePerson)
-	router.PUT("/persons", personHandler.UpdatePerson)
-	router.GET("/persons", personHandler.GetPersons)
-	router.DELETE("/persons/:id", func(c *gin.Context) {
-		reqId := getRequestId(c)
-		uuid := pgtype.UUID{}
-		idParam := c.Param("id")
-
-		log.Debug().
-			Str("request_id", reqId).
-			Str("id_param", idParam).
-			Msg("Processing delete person request")
-
-		if err := uuid.Scan(idParam); err != nil {
-			log.Warn().
-				Err(err).
-				Str("request_id", reqId).
-				Str("id_param", idParam).
-				Msg("Invalid UUID format")
-
-			c.JSON(400, gin.H{"error": "Invalid UUID format"})
-			return
-		}
-		personHandler.DeletePerson(c, uuid)
-	})
-	router.GET("/persons/:id", func(c *gin.Context) {
-		reqId := getRequestId(c)
-		uuid := pgtype.UUID{}
-		idParam := c.Param("id")
-
-		log.Debug().
-			Str("request_id", reqId).
-			Str("id_param", idParam).
-			Msg("Processing get person by ID request")
-
-		if err := uuid.Scan(idParam); err != nil {
-			log.Warn().
-				Err(err).
-				Str("request_id", reqId).
-				Str("id_param", idParam).
-				Msg("Invalid UUID format")
-
-			c.JSON(400, gin.H{"error": "Invalid UUID format"})
-			return
-		}
-		personHandler.GetPersonById(c, uuid)
-	})
-
-	router.GET("/health", func(c *gin.Context) {
-		reqId := getRequestId(c)
-		log.Debug().
-			Str("request_id", reqId).
-			Msg("Health check requested")
-
-		c.JSON(http.StatusOK, gin.H{"status": "ok", "timestamp": time.Now().Unix()})
-	})
+	// Probes, metrics and the swagger UI below stay outside the group and need no credentials.
+	protected := router.Group("/")
+	if cfg.Auth.Enabled {
+		protected.Use(middlerwares.AuthMiddleware(authService))
+	} else {
+		log.Warn().Msg("Authentication is disabled, anyone who can reach the port can change persons")
+	}
+	protected.Use(middlerwares.AuditMiddleware())
+
+	if cfg.RateLimit.Enabled {
+		// Limits are on by default, clients of an upgraded deployment may start getting 429.
+		log.Info().
+			Float64("create_per_minute", cfg.RateLimit.Create.PerMinute).
+			Int32("create_burst", cfg.RateLimit.Create.Burst).
+			Float64("read_per_minute", cfg.RateLimit.Read.PerMinute).
+			Int32("read_burst", cfg.RateLimit.Read.Burst).
+			Str("store", cfg.RateLimit.Store).
+			Msg("Rate limiting is enabled, set RATE_LIMIT_ENABLED=false to turn it off")
+	} else {
+		log.Warn().Msg("Rate limiting is disabled, a single client can exhaust the enrichment quota")
+	}
 
+	readLimit := rateLimit(cfg.RateLimit, rateLimitService, models.RateLimitPolicyRead)
+	createLimit := rateLimit(cfg.RateLimit, rateLimitService, models.RateLimitPolicyCreate)
+
+	readers := protected.Group("", authorize(cfg.Auth, models.RoleReader), readLimit)
+	readers.GET("/persons", personHandler.GetPersons)
+	readers.GET("/persons/:id", withPersonId(personHandler.GetPersonById))
+	readers.GET("/persons/:id/enrichment-results", withPersonId(personHandler.GetEnrichmentResults))
+
+	// Previews are not stored, but they spend the enrichment quota like changes do.
+	editors := protected.Group("", authorize(cfg.Auth, models.RoleEditor), createLimit)
+	editors.POST("/persons", middlerwares.IdempotencyMiddleware(idempotencyService), personHandler.CreatePerson)
+	editors.PUT("/persons", personHandler.UpdatePerson)
+	editors.POST("/persons/:id/enrich", withPersonId(personHandler.EnrichPerson))
+	editors.POST("/enrich", personHandler.PreviewEnrichment)
+	editors.GET("/enrich", personHandler.PreviewEnrichmentByQuery)
+
+	admins := protected.Group("", authorize(cfg.Auth, models.RoleAdmin))
+	admins.DELETE("/persons/:id", createLimit, withPersonId(personHandler.DeletePerson))
+	admins.POST("/persons/enrich", createLimit, personHandler.EnrichPersons)
+	admins.GET("/admin/quota", readLimit, adminHandler.GetQuota)
+
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	// Kept for existing monitoring, answers the same as /livez.
+	router.GET("/health", healthHandler.Livez)
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(problem.NoRoute)
+	router.NoMethod(problem.NoMethod)
+
 	server := &http.Server{
-		Addr:         getServerAddress(),
+		Addr:         ":" + cfg.Server.Port,
 		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	go func() {
@@ -202,7 +278,15 @@ func main() {
 		Str("signal", sig.String()).
 		Msg("Shutdown signal received")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	healthService.SetShuttingDown()
+	if cfg.Server.ShutdownDelay > 0 {
+		log.Info().
+			Dur("delay", cfg.Server.ShutdownDelay).
+			Msg("Waiting for load balancers to stop routing traffic")
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	log.Info().Msg("Shutting down server gracefully")
@@ -210,18 +294,187 @@ func main() {
 		log.Error().Err(err).Msg(custom_errors.ErrShutdownServer.Message)
 	}
 
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Server exited successfully")
 }
 
-func getServerAddress() string {
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
-		log.Debug().Str("port", port).Msg("No port specified, using default")
-	} else {
-		log.Debug().Str("port", port).Msg("Using configured port")
+func connectDatabase(ctx context.Context, databaseConfig config.DatabaseConfig) *pgxpool.Pool {
+	log.Debug().
+		Dur("connect_timeout", databaseConfig.ConnectTimeout).
+		Msg("Connecting to database")
+	dbpool, err := drivers.NewPool(ctx, databaseConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+
+	return dbpool
+}
+
+// authorize returns the role check of a route group. Without authentication there is no
+// principal to check, so every route is open.
+func authorize(authConfig config.AuthConfig, role string) gin.HandlerFunc {
+	if !authConfig.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return middlerwares.RequireRole(role)
+}
+
+// rateLimit returns the rate limit of a route group, or nothing when rate limiting is disabled.
+func rateLimit(rateLimitConfig config.RateLimitConfig, rateLimitService services.RateLimitServiceInterface, policy string) gin.HandlerFunc {
+	if !rateLimitConfig.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return middlerwares.RateLimitMiddleware(rateLimitService, policy)
+}
+
+func newRateLimitService(rateLimitConfig config.RateLimitConfig, dbpool *pgxpool.Pool) *services.RateLimitService {
+	limits := map[string]models.RateLimit{
+		models.RateLimitPolicyCreate: rateLimitConfig.Create.Limit(),
+		models.RateLimitPolicyRead:   rateLimitConfig.Read.Limit(),
+	}
+
+	if rateLimitConfig.Store == config.RateLimitStorePostgres {
+		log.Debug().Msg("Rate limits are shared through the database")
+		return services.NewRateLimitService(drivers.NewRateLimitDriver(dbpool), limits)
+	}
+	return services.NewRateLimitService(drivers.NewMemoryRateLimitDriver(), limits)
+}
+
+func newAuthService(authConfig config.AuthConfig, dbpool *pgxpool.Pool) *services.AuthService {
+	verifier, err := auth.NewVerifier(auth.VerifierOptions{
+		Secret:     authConfig.JwtSecret,
+		JwksFile:   authConfig.JwksFile,
+		Issuer:     authConfig.JwtIssuer,
+		Audience:   authConfig.JwtAudience,
+		RolesClaim: authConfig.JwtRolesClaim,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize bearer token verification")
+	}
+	return services.NewAuthService(drivers.NewApiKeyDriver(dbpool), verifier)
+}
+
+// requireCredentials stops the server when authentication is enabled but no caller could pass
+// it. Deployments upgraded from a version without authentication would otherwise answer every
+// request with 401.
+func requireCredentials(ctx context.Context, authConfig config.AuthConfig, authService services.AuthServiceInterface) {
+	if authConfig.JwtSecret != "" || authConfig.JwksFile != "" {
+		return
+	}
+
+	apiKeys, err := authService.ListApiKeys(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to check that API keys exist, are the migrations applied?")
+	}
+	for _, apiKey := range apiKeys {
+		if !apiKey.IsRevoked() {
+			return
+		}
+	}
+
+	log.Fatal().Msg("Authentication is enabled, but there are no active API keys and neither AUTH_JWT_SECRET " +
+		"nor AUTH_JWKS_FILE is set, so every request would be rejected with 401. Create a key with " +
+		"`apikey create <name> admin`, configure JWT verification or set AUTH_ENABLED=false")
+}
+
+func newEnricher(enrichmentConfig config.EnrichmentConfig, quotaTracker *enrichment.QuotaTracker) *enrichment.Enricher {
+	enricher, err := enrichment.NewConfiguredEnricher(enrichment.EnricherOptions{
+		LocalMode:   enrichmentConfig.LocalMode,
+		DatasetPath: enrichmentConfig.DatasetPath,
+		GenderRules: enrichmentConfig.GenderRules,
+
+		AgeProviders:     enrichmentConfig.AgeProviders,
+		GenderProviders:  enrichmentConfig.GenderProviders,
+		CountryProviders: enrichmentConfig.CountryProviders,
+		Strategy:         enrichmentConfig.Strategy,
+		MinConfidence:    enrichmentConfig.MinConfidence,
+		Weights:          enrichmentConfig.Weights,
+
+		DefaultCountry: enrichmentConfig.DefaultCountry,
+		Localized:      enrichmentConfig.Localized,
+
+		Urls: map[string]string{
+			enrichment.ProviderAgify:       enrichmentConfig.AgeUrl,
+			enrichment.ProviderGenderize:   enrichmentConfig.GenderUrl,
+			enrichment.ProviderNationalize: enrichmentConfig.CountryUrl,
+		},
+		ApiKeys: map[string]string{
+			enrichment.ProviderAgify:       enrichmentConfig.AgeApiKey,
+			enrichment.ProviderGenderize:   enrichmentConfig.GenderApiKey,
+			enrichment.ProviderNationalize: enrichmentConfig.CountryApiKey,
+		},
+		Quota:   quotaTracker,
+		Timeout: enrichmentConfig.Timeout,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize enricher")
+	}
+	return enricher
+}
+
+func withPersonId(handler func(c *gin.Context, personId pgtype.UUID)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqId := getRequestId(c)
+		uuid := pgtype.UUID{}
+		idParam := c.Param("id")
+
+		log.Debug().
+			Str("request_id", reqId).
+			Str("id_param", idParam).
+			Str("path", c.FullPath()).
+			Msg("Processing person request")
+
+		if err := uuid.Scan(idParam); err != nil {
+			log.Warn().
+				Err(err).
+				Str("request_id", reqId).
+				Str("id_param", idParam).
+				Msg("Invalid UUID format")
+
+			problem.Abort(c, custom_errors.ErrInvalidPersonId)
+			return
+		}
+		handler(c, uuid)
+	}
+}
+
+func purgeExpiredIdempotencyKeys(ctx context.Context, idempotencyService services.IdempotencyServiceInterface) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := idempotencyService.PurgeExpired(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to purge expired idempotency keys")
+			}
+		}
+	}
+}
+
+func purgeIdleRateLimitBuckets(ctx context.Context, rateLimitService services.RateLimitServiceInterface) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := rateLimitService.PurgeIdle(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to purge idle rate limit buckets")
+			}
+		}
 	}
-	return ":" + port
 }
 
 func getRequestId(c *gin.Context) string {
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/internal/config"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/models/custom_errors"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) != 1 {
+		log.Fatal().Strs("args", args).Msg("Usage: migrate up|down|status")
+	}
+
+	ctx := context.Background()
+
+	dbpool := connectDatabase(ctx, cfg.Database)
+	defer dbpool.Close()
+
+	migrator, err := drivers.NewMigrator(dbpool)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize migrations")
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to apply migrations")
+		}
+		log.Info().Int("applied_count", len(applied)).Msg("Database is up to date")
+	case "down":
+		if _, err := migrator.Down(ctx); err != nil && !errors.Is(err, custom_errors.ErrNoMigrationToRollBack) {
+			log.Fatal().Err(err).Msg("Failed to roll back migration")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to get migration status")
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		writer.Flush()
+	default:
+		log.Fatal().Str("command", args[0]).Msg("Unknown migrate command. Available commands: up, down, status")
+	}
+}
+
+// autoMigrate applies the pending migrations at startup. Replicas starting together wait on
+// the migrator's advisory lock, so only one of them applies each migration.
+func autoMigrate(ctx context.Context, dbpool *pgxpool.Pool) {
+	migrator, err := drivers.NewMigrator(dbpool)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize migrations")
+	}
+	defer migrator.Close()
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to apply migrations on startup")
+	}
+	log.Info().Int("applied_count", len(applied)).Msg("Database migrations are up to date")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"effective-mobile/internal/config"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/services"
+	"encoding/json"
+	"flag"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+func runReenrich(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("reenrich", flag.ExitOnError)
+	filter := flags.String("filter", "{}", "JSON encoded filters in the same format as the GET /persons body")
+	if err := flags.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Failed to parse reenrich flags")
+	}
+
+	var getPersonDto dtos.GetPersonDto
+	if err := json.Unmarshal([]byte(*filter), &getPersonDto); err != nil {
+		log.Fatal().Err(err).Str("filter", *filter).Msg("Invalid reenrich filter")
+	}
+
+	ctx := context.Background()
+
+	dbpool := connectDatabase(ctx, cfg.Database)
+	defer dbpool.Close()
+
+	personService := services.NewPersonService(drivers.NewPersonDriver(dbpool), newEnricher(cfg.Enrichment, enrichment.NewQuotaTracker()))
+
+	results := enrichAllPersons(ctx, personService, getPersonDto)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		log.Fatal().Err(err).Msg("Failed to write reenrich results")
+	}
+}
+
+// enrichAllPersons repeats EnrichPersons batch by batch until every person matching the filters
+// is processed, or as many as the limit of the filters.
+func enrichAllPersons(ctx context.Context, personService services.PersonServiceInterface, getPersonDto dtos.GetPersonDto) []dtos.EnrichmentResultDto {
+	results := make([]dtos.EnrichmentResultDto, 0)
+
+	remaining := -1
+	if getPersonDto.Limit != nil {
+		remaining = int(*getPersonDto.Limit)
+	}
+	var offset uint32
+	if getPersonDto.Offset != nil {
+		offset = *getPersonDto.Offset
+	}
+
+	for remaining != 0 {
+		if remaining > 0 {
+			limit := uint32(min(remaining, services.MaxEnrichBatchSize))
+			getPersonDto.Limit = &limit
+		}
+		getPersonDto.Offset = &offset
+
+		batch, err := personService.EnrichPersons(ctx, getPersonDto)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to re-enrich persons")
+		}
+		results = append(results, batch.Results...)
+
+		log.Info().
+			Int("processed_count", len(results)).
+			Int64("skipped_count", batch.SkippedCount).
+			Msg("Re-enriched batch of persons")
+
+		if batch.SkippedCount == 0 || len(batch.Results) == 0 {
+			break
+		}
+		if remaining > 0 {
+			remaining -= len(batch.Results)
+		}
+		offset += uint32(len(batch.Results))
+	}
+
+	return results
+}
@@ -16,112 +16,167 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/persons": {
+        "/admin/quota": {
             "get": {
-                "description": "Возвращает список людей согласно указанным фильтрам",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает последние известные лимиты запросов agify, genderize и nationalize. Сервис с исчерпанной квотой не вызывается до её обновления",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "persons"
-                ],
-                "summary": "Получение данных о нескольких людях",
-                "parameters": [
-                    {
-                        "description": "Параметры фильтрации",
-                        "name": "filter",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.GetPersonDto"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "Остаток квот внешних сервисов",
                 "responses": {
                     "200": {
-                        "description": "Список людей",
+                        "description": "Квоты внешних сервисов",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dtos.PersonDto"
+                                "$ref": "#/definitions/dtos.ProviderQuotaDto"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Ошибка валидации запроса",
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера",
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль admin",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновляет существующую запись о человеке на основе переданных данных",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/enrich": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Определяет возраст, пол и национальность по имени так же, как при создании человека, но ничего не сохраняет в БД",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "persons"
+                    "enrichment"
                 ],
-                "summary": "Обновление данных о человеке",
+                "summary": "Предварительное обогащение данных о человеке по параметрам запроса",
                 "parameters": [
                     {
-                        "description": "Информация о человеке для обновления",
-                        "name": "person",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.PersonDto"
-                        }
+                        "type": "string",
+                        "description": "Имя",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фамилия",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Отчество",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код страны ISO 3166-1 alpha-2 для уточнения возраста и пола",
+                        "name": "country_hint",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Обновленная запись о человеке",
+                        "description": "Предполагаемые атрибуты с вероятностями",
                         "schema": {
-                            "$ref": "#/definitions/dtos.PersonDto"
+                            "$ref": "#/definitions/dtos.EnrichmentPreviewDto"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации запроса",
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль editor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "502": {
+                        "description": "Внешние сервисы обогащения вернули ошибку",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Исчерпана квота внешнего сервиса обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "504": {
+                        "description": "Внешние сервисы обогащения не ответили вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Создаёт новую запись о человеке на основе переданных данных",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Определяет возраст, пол и национальность по имени так же, как при создании человека, но ничего не сохраняет в БД",
                 "consumes": [
                     "application/json"
                 ],
@@ -129,9 +184,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "persons"
+                    "enrichment"
                 ],
-                "summary": "Создание новой записи о человеке",
+                "summary": "Предварительное обогащение данных о человеке",
                 "parameters": [
                     {
                         "description": "Информация о человеке",
@@ -144,135 +199,850 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Созданная запись о человеке",
+                    "200": {
+                        "description": "Предполагаемые атрибуты с вероятностями",
                         "schema": {
-                            "$ref": "#/definitions/dtos.PersonDto"
+                            "$ref": "#/definitions/dtos.EnrichmentPreviewDto"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации запроса",
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль editor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "502": {
+                        "description": "Внешние сервисы обогащения вернули ошибку",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Исчерпана квота внешнего сервиса обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "504": {
+                        "description": "Внешние сервисы обогащения не ответили вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/persons/{id}": {
+        "/livez": {
             "get": {
-                "description": "Возвращает информацию о человеке по указанному ID",
+                "description": "Отвечает ok, пока процесс способен обрабатывать запросы. Зависимости не проверяются, поэтому недоступность базы не приводит к перезапуску",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка живости",
+                "responses": {
+                    "200": {
+                        "description": "Процесс жив",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HealthDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список людей согласно указанным фильтрам",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Получение данных о человеке по ID",
+                "summary": "Получение данных о нескольких людях",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "ID человека",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Параметры фильтрации",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GetPersonDto"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Информация о человеке",
+                        "description": "Список людей",
                         "schema": {
-                            "$ref": "#/definitions/dtos.PersonDto"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.PersonDto"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации запроса",
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль reader",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Удаляет запись о человеке по указанному ID",
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет существующую запись о человеке на основе переданных данных. Значения проверяются так же, как при создании: возраст от 0 до 150, пол male или female, страна - код ISO 3166-1 alpha-2 (регистр не важен, сохраняется в верхнем регистре)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Удаление записи о человеке",
+                "summary": "Обновление данных о человеке",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "ID человека",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Информация о человеке для обновления",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PersonDto"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Сообщение об успешном удалении",
+                        "description": "Обновленная запись о человеке",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.PersonDto"
                         }
                     },
                     "400": {
-                        "description": "Ошибка валидации запроса",
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль editor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dtos.ProblemDto"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт новую запись о человеке на основе переданных данных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Создание новой записи о человеке",
+                "parameters": [
+                    {
+                        "description": "Информация о человеке",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreatePersonDto"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная запись о человеке",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PersonDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль editor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "409": {
+                        "description": "Запрос с этим ключом идемпотентности ещё обрабатывается",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "422": {
+                        "description": "Ключ идемпотентности уже использован с другим телом запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "502": {
+                        "description": "Внешние сервисы обогащения вернули ошибку",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Исчерпана квота внешнего сервиса обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "504": {
+                        "description": "Внешние сервисы обогащения не ответили вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/enrich": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Повторно определяет возраст, пол и национальность людей, подходящих под фильтры. Поля, изменённые вручную, не перезаписываются. За один запрос обрабатывается не больше 20 человек (и не больше limit), число необработанных возвращается в skipped_count",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Повторное обогащение данных о нескольких людях",
+                "parameters": [
+                    {
+                        "description": "Параметры фильтрации",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GetPersonDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результаты повторного обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.EnrichmentBatchDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль admin",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "502": {
+                        "description": "Внешние сервисы обогащения вернули ошибку",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Исчерпана квота внешнего сервиса обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "504": {
+                        "description": "Внешние сервисы обогащения не ответили вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает информацию о человеке по указанному ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Получение данных о человеке по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID человека",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Информация о человеке",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PersonDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль reader",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет запись о человеке по указанному ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Удаление записи о человеке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID человека",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщение об успешном удалении",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль admin",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/{id}/enrich": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Повторно определяет возраст, пол и национальность человека. Поля, изменённые вручную через UpdatePerson, не перезаписываются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Повторное обогащение данных о человеке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID человека",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат повторного обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.EnrichmentResultDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль editor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "502": {
+                        "description": "Внешние сервисы обогащения вернули ошибку",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Исчерпана квота внешнего сервиса обогащения",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "504": {
+                        "description": "Внешние сервисы обогащения не ответили вовремя",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/persons/{id}/enrichment-results": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает ответы всех источников, рассмотренных при обогащении возраста, пола и национальности, с отметкой выбранного",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Получение истории обогащения человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID человека",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответы источников",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.EnrichmentProviderResultDto"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации запроса, ошибки полей в errors",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Не переданы или неверны учётные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав, требуется роль reader",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышен лимит запросов, время ожидания в заголовке Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет доступность базы, отсутствие непримененных миграций и состояние внешних сервисов обогащения. Исчерпанная квота сервиса отображается как открытый circuit и не снимает готовность. Во время плавной остановки всегда возвращает 503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "Сервис готов принимать запросы",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ReadinessDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Критическая зависимость недоступна или сервис останавливается",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ReadinessDto"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dtos.AgeEstimateDto": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.AttributeSourceDto": {
+            "type": "object",
+            "properties": {
+                "source": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CountryEstimateDto": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.CountryIdDto"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "probability": {
+                    "type": "number"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CountryIdDto": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
         "dtos.CreatePersonDto": {
             "type": "object",
             "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "country_hint": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -284,18 +1054,200 @@ const docTemplate = `{
                 }
             }
         },
+        "dtos.DependencyCheckDto": {
+            "type": "object",
+            "properties": {
+                "circuit": {
+                    "type": "string"
+                },
+                "critical": {
+                    "description": "Critical зависимости в статусе down переводят сервис в not_ready",
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "retry_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status: up, down или degraded",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.EnrichmentBatchDto": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.EnrichmentResultDto"
+                    }
+                },
+                "skipped_count": {
+                    "description": "SkippedCount людей, подходящих под фильтры после offset, но не обработанных из-за limit или\nограничения размера пакета. Для продолжения повторите запрос с offset, увеличенным на размер results",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "dtos.EnrichmentChangeDto": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new_value": {
+                    "type": "string"
+                },
+                "old_value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.EnrichmentPreviewDto": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "$ref": "#/definitions/dtos.AgeEstimateDto"
+                },
+                "country": {
+                    "$ref": "#/definitions/dtos.CountryEstimateDto"
+                },
+                "gender": {
+                    "$ref": "#/definitions/dtos.GenderEstimateDto"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.EnrichmentProviderResultDto": {
+            "type": "object",
+            "properties": {
+                "chosen": {
+                    "type": "boolean"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.EnrichmentResultDto": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.EnrichmentChangeDto"
+                    }
+                },
+                "error": {
+                    "description": "Error описывает, почему человека не удалось обработать при массовом обогащении",
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode этой ошибки, те же стабильные коды, что и в ответах об ошибках",
+                    "type": "string",
+                    "example": "enrichment_failed"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "skipped_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.FieldErrorDto": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "out_of_range"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "age"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "age must be between 0 and 150"
+                }
+            }
+        },
+        "dtos.GenderEstimateDto": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "probability": {
+                    "type": "number"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "dtos.GetPersonDto": {
             "type": "object",
             "properties": {
+                "age_sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "countries": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "country_sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "gender": {
                     "type": "string"
                 },
+                "gender_sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "high_age": {
                     "type": "integer"
                 },
@@ -334,18 +1286,53 @@ const docTemplate = `{
                 }
             }
         },
+        "dtos.HealthDto": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
         "dtos.PersonDto": {
             "type": "object",
             "properties": {
                 "age": {
                     "type": "integer"
                 },
+                "age_source": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dtos.AttributeSourceDto"
+                        }
+                    ],
+                    "readOnly": true
+                },
                 "country": {
                     "type": "string"
                 },
+                "country_source": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dtos.AttributeSourceDto"
+                        }
+                    ],
+                    "readOnly": true
+                },
                 "gender": {
                     "type": "string"
                 },
+                "gender_source": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dtos.AttributeSourceDto"
+                        }
+                    ],
+                    "readOnly": true
+                },
                 "id": {
                     "type": "string"
                 },
@@ -359,6 +1346,123 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "dtos.ProblemDto": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code стабильный машиночитаемый код ошибки",
+                    "type": "string",
+                    "example": "person_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "person not found"
+                },
+                "errors": {
+                    "description": "Errors ошибки отдельных полей запроса",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.FieldErrorDto"
+                    }
+                },
+                "instance": {
+                    "description": "Instance путь запроса, вызвавшего ошибку",
+                    "type": "string",
+                    "example": "/persons/0195b4a2-5f1e-7c3a-9d4e-1a2b3c4d5e6f"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "5f0c2d9e-8a41-4c7b-9a53-3e1f7d2b6c10"
+                },
+                "required_role": {
+                    "description": "RequiredRole роль, необходимая для операции, только для code=forbidden",
+                    "type": "string",
+                    "example": "editor"
+                },
+                "roles": {
+                    "description": "Roles роли вызывающего, только для code=forbidden",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "reader"
+                    ]
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:effective-mobile:problem:person_not_found"
+                }
+            }
+        },
+        "dtos.ProviderQuotaDto": {
+            "type": "object",
+            "properties": {
+                "api_key_configured": {
+                    "type": "boolean"
+                },
+                "exhausted": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "reset_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ReadinessDto": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.DependencyCheckDto"
+                    }
+                },
+                "shutting_down": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Статический API-ключ, выпускается командой apikey create",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT в формате \"Bearer \u003cтокен\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
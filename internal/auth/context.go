@@ -0,0 +1,29 @@
+// Package auth keeps the authenticated principal in context.Context and verifies the JWT bearer
+// tokens accepted by the API.
+package auth
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+type contextKey struct{}
+
+// NewContext stores the principal in ctx and adds it to the request logger, so the service and
+// driver logs of the request say who made it.
+func NewContext(ctx context.Context, principal *models.Principal) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, principal)
+	logger := log.Ctx(ctx).With().
+		Str("principal", principal.Subject).
+		Str("auth_method", principal.Method).
+		Strs("roles", principal.Roles).
+		Logger()
+	return logger.WithContext(ctx)
+}
+
+// FromContext returns the principal stored in ctx, or nil for unauthenticated requests.
+func FromContext(ctx context.Context) *models.Principal {
+	principal, _ := ctx.Value(contextKey{}).(*models.Principal)
+	return principal
+}
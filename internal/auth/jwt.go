@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto"
+	"effective-mobile/internal/models/custom_errors"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// leeway tolerates clock skew between the token issuer and the service.
+const leeway = 30 * time.Second
+
+const defaultRolesClaim = "roles"
+
+var (
+	hmacMethods       = []string{"HS256", "HS384", "HS512"}
+	asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+type VerifierOptions struct {
+	// Secret verifies HMAC signed tokens. It is mutually exclusive with JwksFile.
+	Secret string
+	// JwksFile is the path of a JWK set with the public keys of the token issuer.
+	JwksFile string
+	// Issuer and Audience are checked against the iss and aud claims when set.
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim with the caller roles, nested claims are separated by dots,
+	// for example realm_access.roles. The default is roles.
+	RolesClaim string
+}
+
+// Claims are the token claims used by the service.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Roles are read from the configured roles claim, which is either an array of strings or a
+	// space separated string.
+	Roles []string `json:"-"`
+
+	all map[string]any
+}
+
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.RegisteredClaims); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.all)
+}
+
+type Verifier struct {
+	parser     *jwt.Parser
+	keyFunc    jwt.Keyfunc
+	rolesClaim []string
+}
+
+// NewVerifier returns nil when neither a secret nor a JWKS file is configured, bearer tokens
+// are rejected then.
+func NewVerifier(options VerifierOptions) (*Verifier, error) {
+	parserOptions := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if options.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(options.Issuer))
+	}
+	if options.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(options.Audience))
+	}
+
+	rolesClaim := options.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = defaultRolesClaim
+	}
+
+	switch {
+	case options.Secret != "":
+		log.Debug().Msg("Bearer tokens are verified with the shared secret")
+		secret := []byte(options.Secret)
+		return &Verifier{
+			parser: jwt.NewParser(append(parserOptions, jwt.WithValidMethods(hmacMethods))...),
+			keyFunc: func(*jwt.Token) (any, error) {
+				return secret, nil
+			},
+			rolesClaim: strings.Split(rolesClaim, "."),
+		}, nil
+	case options.JwksFile != "":
+		keys, err := LoadJwks(options.JwksFile)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("path", options.JwksFile).
+				Msg(custom_errors.ErrLoadJwks.Message)
+			return nil, &custom_errors.InternalError{Message: custom_errors.ErrLoadJwks.Message, Err: err}
+		}
+		log.Debug().
+			Str("path", options.JwksFile).
+			Int("keys_count", len(keys)).
+			Msg("Bearer tokens are verified with the JWKS keys")
+		return &Verifier{
+			parser:     jwt.NewParser(append(parserOptions, jwt.WithValidMethods(asymmetricMethods))...),
+			keyFunc:    jwksKeyFunc(keys),
+			rolesClaim: strings.Split(rolesClaim, "."),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Verify checks the signature, expiry, issuer and audience of the token and requires a subject.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	claims.Roles = roles(claims.all, v.rolesClaim)
+	return claims, nil
+}
+
+func roles(claims map[string]any, path []string) []string {
+	var value any = claims
+	for _, name := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		roles := make([]string, 0, len(value))
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
+
+// jwksKeyFunc picks the key by the kid header. A token without kid is accepted only when the
+// set has a single key.
+func jwksKeyFunc(keys map[string]crypto.PublicKey) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, errors.New("unknown signing key")
+	}
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"effective-mobile/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.Claims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "service-a",
+		Issuer:    "issuer",
+		Audience:  jwt.ClaimStrings{"person-api"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func encode(value *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, size)))
+}
+
+func writeJwks(t *testing.T, keys ...map[string]string) string {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestNewVerifierWithoutKeys(t *testing.T) {
+	verifier, err := NewVerifier(VerifierOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, verifier)
+}
+
+func TestVerifyWithSecret(t *testing.T) {
+	verifier, err := NewVerifier(VerifierOptions{Secret: testSecret, Issuer: "issuer", Audience: "person-api"})
+	require.NoError(t, err)
+
+	t.Run("Valid token", func(t *testing.T) {
+		claims, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, "service-a", claims.Subject)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte("another secret of the same length"), "", validClaims()))
+		assert.Error(t, err)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		claims := validClaims()
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("Token without expiry", func(t *testing.T) {
+		claims := validClaims()
+		claims.ExpiresAt = nil
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims))
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong issuer", func(t *testing.T) {
+		claims := validClaims()
+		claims.Issuer = "other"
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+
+	t.Run("Wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims.Audience = jwt.ClaimStrings{"other"}
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("Token without subject", func(t *testing.T) {
+		claims := validClaims()
+		claims.Subject = ""
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims))
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsigned token", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims()))
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifyReadsRoles(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name       string
+		rolesClaim string
+		claims     jwt.MapClaims
+		expected   []string
+	}{
+		{
+			name:     "Array claim",
+			claims:   jwt.MapClaims{"roles": []string{"reader", "editor"}},
+			expected: []string{"reader", "editor"},
+		},
+		{
+			name:     "Space separated claim",
+			claims:   jwt.MapClaims{"roles": "reader admin"},
+			expected: []string{"reader", "admin"},
+		},
+		{
+			name:       "Nested claim",
+			rolesClaim: "realm_access.roles",
+			claims:     jwt.MapClaims{"realm_access": map[string]any{"roles": []string{"admin"}}},
+			expected:   []string{"admin"},
+		},
+		{
+			name:   "Missing claim",
+			claims: jwt.MapClaims{"scope": "reader"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewVerifier(VerifierOptions{Secret: testSecret, RolesClaim: tt.rolesClaim})
+			require.NoError(t, err)
+
+			tt.claims["sub"] = "service-a"
+			tt.claims["exp"] = expiresAt
+			claims, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", tt.claims))
+
+			require.NoError(t, err)
+			assert.Equal(t, "service-a", claims.Subject)
+			if tt.expected == nil {
+				assert.Empty(t, claims.Roles)
+			} else {
+				assert.Equal(t, tt.expected, claims.Roles)
+			}
+		})
+	}
+}
+
+func TestVerifyWithJwks(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := writeJwks(t,
+		map[string]string{
+			"kty": "RSA",
+			"kid": "rsa",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		map[string]string{
+			"kty": "EC",
+			"kid": "ec",
+			"crv": "P-256",
+			"x":   encode(ecKey.X, 32),
+			"y":   encode(ecKey.Y, 32),
+		},
+		map[string]string{"kty": "oct", "kid": "enc", "use": "enc"},
+	)
+
+	verifier, err := NewVerifier(VerifierOptions{JwksFile: path})
+	require.NoError(t, err)
+
+	t.Run("RSA signed token", func(t *testing.T) {
+		claims, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, "service-a", claims.Subject)
+	})
+
+	t.Run("EC signed token", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodES256, ecKey, "ec", validClaims()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown kid", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, rsaKey, "other", validClaims()))
+		assert.Error(t, err)
+	})
+
+	t.Run("HMAC token signed with the public key", func(t *testing.T) {
+		_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, rsaKey.N.Bytes(), "rsa", validClaims()))
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadJwksErrors(t *testing.T) {
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := NewVerifier(VerifierOptions{JwksFile: filepath.Join(t.TempDir(), "missing.json")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Point not on curve", func(t *testing.T) {
+		path := writeJwks(t, map[string]string{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   encode(big.NewInt(1), 32),
+			"y":   encode(big.NewInt(1), 32),
+		})
+		_, err := LoadJwks(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("No signature keys", func(t *testing.T) {
+		_, err := LoadJwks(writeJwks(t))
+		assert.Error(t, err)
+	})
+}
+
+func TestPrincipalContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+
+	principal := &models.Principal{Subject: "billing", Method: models.AuthMethodApiKey}
+	assert.Equal(t, principal, FromContext(NewContext(ctx, principal)))
+}
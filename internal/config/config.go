@@ -0,0 +1,661 @@
+// Package config loads the application settings from the environment, the .env file and an
+// optional YAML file. Environment variables (including the ones from .env) override the YAML
+// file, which overrides the defaults.
+package config
+
+import (
+	"bytes"
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/redact"
+	"effective-mobile/internal/tracing"
+	"errors"
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const EnvProduction = "production"
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// minJwtSecretLength is the size of the SHA-256 output, shorter HMAC secrets can be brute forced.
+const minJwtSecretLength = 32
+
+var (
+	countryIdRegexp = regexp.MustCompile(`^[A-Z]{2}$`)
+
+	logLevels = []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
+
+	localModes  = []string{"", enrichment.LocalModeOff, enrichment.LocalModePrimary, enrichment.LocalModeFallback}
+	genderRules = []string{"", enrichment.GenderRulesOff, enrichment.GenderRulesBefore, enrichment.GenderRulesInstead}
+	strategies  = []string{"", enrichment.StrategyFallback, enrichment.StrategyVote}
+
+	ageProviders     = []string{enrichment.ProviderAgify, enrichment.ProviderLocal}
+	genderProviders  = []string{enrichment.ProviderGenderize, enrichment.ProviderRules, enrichment.ProviderLocal}
+	countryProviders = []string{enrichment.ProviderNationalize, enrichment.ProviderLocal}
+
+	tracingExporters = []string{tracing.ExporterNone, tracing.ExporterOtlp, tracing.ExporterStdout}
+
+	rateLimitStores = []string{RateLimitStoreMemory, RateLimitStorePostgres}
+)
+
+type Config struct {
+	// Env is "production" for release mode and JSON logs, anything else means development.
+	Env         string            `yaml:"env"`
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Enrichment  EnrichmentConfig  `yaml:"enrichment"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Auth        AuthConfig        `yaml:"auth"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+}
+
+type ServerConfig struct {
+	Port            string        `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ShutdownDelay is how long /readyz answers not ready before the server stops accepting
+	// connections, so load balancers have time to take the instance out of rotation.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+}
+
+type DatabaseConfig struct {
+	ConnectionString string `yaml:"connection_string"`
+
+	// MaxConns of zero keeps the pgx default of max(4, number of CPUs).
+	MaxConns          int32         `yaml:"max_conns"`
+	MinConns          int32         `yaml:"min_conns"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+	// StatementTimeout is set as the statement_timeout of every connection. Zero means no limit.
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+	// ConnectTimeout is how long the startup waits for the database to answer a ping.
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	// AutoMigrate applies the pending migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type LoggingConfig struct {
+	Level string `yaml:"level"`
+	// Redaction overrides the log policy (keep, mask, hash or drop) of person fields.
+	Redaction     map[string]string `yaml:"redaction"`
+	RedactionSalt string            `yaml:"redaction_salt"`
+	// DebugSensitive enables debug logs with query text, raw payloads and per-field values.
+	DebugSensitive bool `yaml:"debug_sensitive"`
+}
+
+type IdempotencyConfig struct {
+	Ttl time.Duration `yaml:"ttl"`
+}
+
+type EnrichmentConfig struct {
+	AgeUrl     string `yaml:"age_url"`
+	GenderUrl  string `yaml:"gender_url"`
+	CountryUrl string `yaml:"country_url"`
+
+	AgeApiKey     string `yaml:"age_api_key"`
+	GenderApiKey  string `yaml:"gender_api_key"`
+	CountryApiKey string `yaml:"country_api_key"`
+
+	LocalMode   string `yaml:"local_mode"`
+	DatasetPath string `yaml:"dataset_path"`
+	GenderRules string `yaml:"gender_rules"`
+
+	AgeProviders     []string           `yaml:"age_providers"`
+	GenderProviders  []string           `yaml:"gender_providers"`
+	CountryProviders []string           `yaml:"country_providers"`
+	Strategy         string             `yaml:"strategy"`
+	MinConfidence    float64            `yaml:"min_confidence"`
+	Weights          map[string]float64 `yaml:"weights"`
+
+	DefaultCountry string        `yaml:"default_country"`
+	Localized      bool          `yaml:"localized"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, otlp or stdout. With none spans are not recorded, but the incoming
+	// trace context is still passed on to the enrichment APIs.
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type AuthConfig struct {
+	// Enabled requires an API key or a bearer token for the person and admin routes. Probes,
+	// metrics and the swagger UI stay public.
+	Enabled bool `yaml:"enabled"`
+	// JwtSecret verifies HMAC signed tokens, JwksFile RSA and EC signed ones. At most one of
+	// them is set, without both only API keys are accepted.
+	JwtSecret   string `yaml:"jwt_secret"`
+	JwksFile    string `yaml:"jwks_file"`
+	JwtIssuer   string `yaml:"jwt_issuer"`
+	JwtAudience string `yaml:"jwt_audience"`
+	// JwtRolesClaim is the claim with the reader, editor or admin roles of the caller, nested
+	// claims are separated by dots.
+	JwtRolesClaim string `yaml:"jwt_roles_claim"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Store is memory for limits per replica or postgres for limits shared by all replicas.
+	Store string `yaml:"store"`
+	// Create limits the routes that change persons or call the enrichment APIs, Read the others.
+	Create RateLimitRule `yaml:"create"`
+	Read   RateLimitRule `yaml:"read"`
+}
+
+// RateLimitRule allows Burst requests at once and PerMinute requests per minute on average.
+type RateLimitRule struct {
+	PerMinute float64 `yaml:"per_minute"`
+	Burst     int32   `yaml:"burst"`
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 5 * time.Second,
+			ShutdownDelay:   5 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+			ConnectTimeout:    30 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Idempotency: IdempotencyConfig{
+			Ttl: 24 * time.Hour,
+		},
+		Enrichment: EnrichmentConfig{
+			AgeUrl:     "https://api.agify.io/?name=",
+			GenderUrl:  "https://api.genderize.io/?name=",
+			CountryUrl: "https://api.nationalize.io/?name=",
+			Timeout:    10 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			ServiceName: "person-api",
+			SampleRatio: 1,
+		},
+		Auth: AuthConfig{
+			Enabled:       true,
+			JwtRolesClaim: "roles",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   RateLimitStoreMemory,
+			Create:  RateLimitRule{PerMinute: 60, Burst: 10},
+			Read:    RateLimitRule{PerMinute: 600, Burst: 100},
+		},
+	}
+}
+
+// Load reads .env from the working directory, then the YAML file named by CONFIG_FILE, then
+// the environment, and validates the result.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Error().Err(err).Msg(custom_errors.ErrEnvLoading.Message)
+		return nil, &custom_errors.InternalError{Message: custom_errors.ErrEnvLoading.Message, Err: err}
+	}
+
+	return load(os.LookupEnv)
+}
+
+func load(lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+
+	if path, ok := lookupEnv("CONFIG_FILE"); ok && path != "" {
+		if err := loadFile(path, config); err != nil {
+			return nil, err
+		}
+	}
+
+	env := &envReader{lookup: lookupEnv}
+	env.apply(config)
+
+	problems := append(env.problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, &custom_errors.ConfigError{Problems: problems}
+	}
+
+	log.Debug().
+		Str("env", config.Env).
+		Str("port", config.Server.Port).
+		Str("log_level", config.Logging.Level).
+		Msg("Configuration loaded")
+
+	return config, nil
+}
+
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("path", path).
+			Msg(custom_errors.ErrConfigLoading.Message)
+		return &custom_errors.InternalError{Message: custom_errors.ErrConfigLoading.Message, Err: err}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		log.Error().
+			Err(err).
+			Str("path", path).
+			Msg(custom_errors.ErrConfigLoading.Message)
+		return &custom_errors.InternalError{Message: custom_errors.ErrConfigLoading.Message, Err: err}
+	}
+
+	log.Debug().Str("path", path).Msg("Config file loaded")
+	return nil
+}
+
+// envReader copies the set environment variables into the config and collects parse errors.
+type envReader struct {
+	lookup   func(string) (string, bool)
+	problems []string
+}
+
+func (r *envReader) apply(config *Config) {
+	r.string("ENV", &config.Env)
+
+	r.string("SERVER_PORT", &config.Server.Port)
+	r.duration("SERVER_READ_TIMEOUT", &config.Server.ReadTimeout)
+	r.duration("SERVER_WRITE_TIMEOUT", &config.Server.WriteTimeout)
+	r.duration("SERVER_IDLE_TIMEOUT", &config.Server.IdleTimeout)
+	r.duration("SERVER_SHUTDOWN_TIMEOUT", &config.Server.ShutdownTimeout)
+	r.duration("SERVER_SHUTDOWN_DELAY", &config.Server.ShutdownDelay)
+
+	r.string("DB_CONNECTION_STRING", &config.Database.ConnectionString)
+	r.int32("DB_MAX_CONNS", &config.Database.MaxConns)
+	r.int32("DB_MIN_CONNS", &config.Database.MinConns)
+	r.duration("DB_MAX_CONN_LIFETIME", &config.Database.MaxConnLifetime)
+	r.duration("DB_MAX_CONN_IDLE_TIME", &config.Database.MaxConnIdleTime)
+	r.duration("DB_HEALTH_CHECK_PERIOD", &config.Database.HealthCheckPeriod)
+	r.duration("DB_STATEMENT_TIMEOUT", &config.Database.StatementTimeout)
+	r.duration("DB_CONNECT_TIMEOUT", &config.Database.ConnectTimeout)
+	r.bool("DB_AUTO_MIGRATE", &config.Database.AutoMigrate)
+
+	r.string("LOG_LEVEL", &config.Logging.Level)
+	config.Logging.Level = strings.ToLower(config.Logging.Level)
+	r.pairs("LOG_REDACTION", &config.Logging.Redaction)
+	r.string("LOG_REDACTION_SALT", &config.Logging.RedactionSalt)
+	r.bool("LOG_DEBUG_SENSITIVE", &config.Logging.DebugSensitive)
+
+	r.duration("IDEMPOTENCY_TTL", &config.Idempotency.Ttl)
+
+	enrichmentConfig := &config.Enrichment
+	r.string("AGE_URL", &enrichmentConfig.AgeUrl)
+	r.string("GENDER_URL", &enrichmentConfig.GenderUrl)
+	r.string("COUNTRY_URL", &enrichmentConfig.CountryUrl)
+	r.string("AGE_API_KEY", &enrichmentConfig.AgeApiKey)
+	r.string("GENDER_API_KEY", &enrichmentConfig.GenderApiKey)
+	r.string("COUNTRY_API_KEY", &enrichmentConfig.CountryApiKey)
+	r.string("ENRICHMENT_LOCAL_MODE", &enrichmentConfig.LocalMode)
+	r.string("ENRICHMENT_DATASET_PATH", &enrichmentConfig.DatasetPath)
+	r.string("ENRICHMENT_GENDER_RULES", &enrichmentConfig.GenderRules)
+	r.list("ENRICHMENT_AGE_PROVIDERS", &enrichmentConfig.AgeProviders)
+	r.list("ENRICHMENT_GENDER_PROVIDERS", &enrichmentConfig.GenderProviders)
+	r.list("ENRICHMENT_COUNTRY_PROVIDERS", &enrichmentConfig.CountryProviders)
+	r.string("ENRICHMENT_STRATEGY", &enrichmentConfig.Strategy)
+	r.float("ENRICHMENT_MIN_CONFIDENCE", &enrichmentConfig.MinConfidence)
+	r.weights("ENRICHMENT_PROVIDER_WEIGHTS", &enrichmentConfig.Weights)
+	r.string("ENRICHMENT_DEFAULT_COUNTRY", &enrichmentConfig.DefaultCountry)
+	r.bool("ENRICHMENT_LOCALIZED", &enrichmentConfig.Localized)
+	r.duration("ENRICHMENT_TIMEOUT", &enrichmentConfig.Timeout)
+
+	r.string("TRACING_EXPORTER", &config.Tracing.Exporter)
+	r.string("TRACING_ENDPOINT", &config.Tracing.Endpoint)
+	r.bool("TRACING_INSECURE", &config.Tracing.Insecure)
+	r.string("TRACING_SERVICE_NAME", &config.Tracing.ServiceName)
+	r.float("TRACING_SAMPLE_RATIO", &config.Tracing.SampleRatio)
+
+	r.bool("AUTH_ENABLED", &config.Auth.Enabled)
+	r.string("AUTH_JWT_SECRET", &config.Auth.JwtSecret)
+	r.string("AUTH_JWKS_FILE", &config.Auth.JwksFile)
+	r.string("AUTH_JWT_ISSUER", &config.Auth.JwtIssuer)
+	r.string("AUTH_JWT_AUDIENCE", &config.Auth.JwtAudience)
+	r.string("AUTH_JWT_ROLES_CLAIM", &config.Auth.JwtRolesClaim)
+
+	r.bool("RATE_LIMIT_ENABLED", &config.RateLimit.Enabled)
+	r.string("RATE_LIMIT_STORE", &config.RateLimit.Store)
+	r.float("RATE_LIMIT_CREATE_PER_MINUTE", &config.RateLimit.Create.PerMinute)
+	r.int32("RATE_LIMIT_CREATE_BURST", &config.RateLimit.Create.Burst)
+	r.float("RATE_LIMIT_READ_PER_MINUTE", &config.RateLimit.Read.PerMinute)
+	r.int32("RATE_LIMIT_READ_BURST", &config.RateLimit.Read.Burst)
+}
+
+// value returns the variable when it is set to a non-empty value, empty variables keep the
+// default like before.
+func (r *envReader) value(name string) (string, bool) {
+	value, ok := r.lookup(name)
+	if !ok || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+func (r *envReader) string(name string, target *string) {
+	if value, ok := r.value(name); ok {
+		*target = value
+	}
+}
+
+func (r *envReader) duration(name string, target *time.Duration) {
+	if value, ok := r.value(name); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			r.problems = append(r.problems, name+": invalid duration "+strconv.Quote(value))
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (r *envReader) int32(name string, target *int32) {
+	if value, ok := r.value(name); ok {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			r.problems = append(r.problems, name+": invalid integer "+strconv.Quote(value))
+			return
+		}
+		*target = int32(parsed)
+	}
+}
+
+func (r *envReader) float(name string, target *float64) {
+	if value, ok := r.value(name); ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			r.problems = append(r.problems, name+": invalid number "+strconv.Quote(value))
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (r *envReader) bool(name string, target *bool) {
+	if value, ok := r.value(name); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			r.problems = append(r.problems, name+": invalid boolean "+strconv.Quote(value))
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (r *envReader) list(name string, target *[]string) {
+	if value, ok := r.value(name); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*target = items
+	}
+}
+
+func (r *envReader) pairs(name string, target *map[string]string) {
+	if value, ok := r.value(name); ok {
+		parsed := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			key, item, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if !found {
+				r.problems = append(r.problems, name+": expected key=value pairs, got "+strconv.Quote(value))
+				return
+			}
+			parsed[strings.TrimSpace(key)] = strings.TrimSpace(item)
+		}
+		*target = parsed
+	}
+}
+
+func (r *envReader) weights(name string, target *map[string]float64) {
+	if value, ok := r.value(name); ok {
+		parsed, err := enrichment.ParseWeights(value)
+		if err != nil {
+			r.problems = append(r.problems, name+": expected provider=weight pairs, got "+strconv.Quote(value))
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		add("SERVER_PORT: expected a port number, got " + strconv.Quote(c.Server.Port))
+	}
+	positive := func(name string, value time.Duration) {
+		if value <= 0 {
+			add(name + ": must be positive")
+		}
+	}
+	positive("SERVER_READ_TIMEOUT", c.Server.ReadTimeout)
+	positive("SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	positive("SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	positive("SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+	if c.Server.ShutdownDelay < 0 {
+		add("SERVER_SHUTDOWN_DELAY: must not be negative")
+	}
+	positive("IDEMPOTENCY_TTL", c.Idempotency.Ttl)
+
+	problems = append(problems, c.Database.validate()...)
+
+	if !slices.Contains(logLevels, c.Logging.Level) {
+		add("LOG_LEVEL: expected one of " + strings.Join(logLevels, ", ") + ", got " + strconv.Quote(c.Logging.Level))
+	}
+	for _, field := range slices.Sorted(maps.Keys(c.Logging.Redaction)) {
+		if !slices.Contains(redact.Fields, field) {
+			add("LOG_REDACTION: unknown field " + strconv.Quote(field) + ", expected " + strings.Join(redact.Fields, ", "))
+		}
+		if policy := c.Logging.Redaction[field]; !slices.Contains(redact.Policies, policy) {
+			add("LOG_REDACTION: expected one of " + strings.Join(redact.Policies, ", ") + " for " + strconv.Quote(field) + ", got " + strconv.Quote(policy))
+		}
+	}
+
+	problems = append(problems, c.Enrichment.validate()...)
+	problems = append(problems, c.Tracing.validate()...)
+	problems = append(problems, c.Auth.validate()...)
+	return append(problems, c.RateLimit.validate()...)
+}
+
+func (c *DatabaseConfig) validate() []string {
+	var problems []string
+	add := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	if c.ConnectionString == "" {
+		add("DB_CONNECTION_STRING: is required")
+	}
+	if c.MaxConns < 0 {
+		add("DB_MAX_CONNS: must not be negative")
+	}
+	if c.MinConns < 0 {
+		add("DB_MIN_CONNS: must not be negative")
+	}
+	if c.MaxConns > 0 && c.MinConns > c.MaxConns {
+		add("DB_MIN_CONNS: must not exceed DB_MAX_CONNS")
+	}
+	if c.MaxConnLifetime <= 0 {
+		add("DB_MAX_CONN_LIFETIME: must be positive")
+	}
+	if c.MaxConnIdleTime <= 0 {
+		add("DB_MAX_CONN_IDLE_TIME: must be positive")
+	}
+	if c.HealthCheckPeriod <= 0 {
+		add("DB_HEALTH_CHECK_PERIOD: must be positive")
+	}
+	if c.StatementTimeout < 0 {
+		add("DB_STATEMENT_TIMEOUT: must not be negative")
+	}
+	if c.ConnectTimeout <= 0 {
+		add("DB_CONNECT_TIMEOUT: must be positive")
+	}
+
+	return problems
+}
+
+func (c *EnrichmentConfig) validate() []string {
+	var problems []string
+	add := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	httpUrl := func(name string, value string) {
+		if parsed, err := url.Parse(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add(name + ": expected an http(s) URL, got " + strconv.Quote(value))
+		}
+	}
+	httpUrl("AGE_URL", c.AgeUrl)
+	httpUrl("GENDER_URL", c.GenderUrl)
+	httpUrl("COUNTRY_URL", c.CountryUrl)
+
+	oneOf := func(name string, value string, allowed []string) {
+		if !slices.Contains(allowed, value) {
+			add(name + ": expected one of " + strings.Join(allowed[1:], ", ") + ", got " + strconv.Quote(value))
+		}
+	}
+	oneOf("ENRICHMENT_LOCAL_MODE", c.LocalMode, localModes)
+	oneOf("ENRICHMENT_GENDER_RULES", c.GenderRules, genderRules)
+	oneOf("ENRICHMENT_STRATEGY", c.Strategy, strategies)
+
+	known := func(name string, providers []string, allowed []string) {
+		for _, provider := range providers {
+			if !slices.Contains(allowed, provider) {
+				add(name + ": unknown provider " + strconv.Quote(provider) + ", expected " + strings.Join(allowed, ", "))
+			}
+		}
+	}
+	known("ENRICHMENT_AGE_PROVIDERS", c.AgeProviders, ageProviders)
+	known("ENRICHMENT_GENDER_PROVIDERS", c.GenderProviders, genderProviders)
+	known("ENRICHMENT_COUNTRY_PROVIDERS", c.CountryProviders, countryProviders)
+
+	if c.MinConfidence < 0 || c.MinConfidence > 1 {
+		add("ENRICHMENT_MIN_CONFIDENCE: must be between 0 and 1")
+	}
+	for provider, weight := range c.Weights {
+		if weight < 0 {
+			add("ENRICHMENT_PROVIDER_WEIGHTS: negative weight for " + strconv.Quote(provider))
+		}
+	}
+	if c.DefaultCountry != "" && !countryIdRegexp.MatchString(c.DefaultCountry) {
+		add("ENRICHMENT_DEFAULT_COUNTRY: expected an ISO 3166-1 alpha-2 code, got " + strconv.Quote(c.DefaultCountry))
+	}
+	if c.Timeout < 0 {
+		add("ENRICHMENT_TIMEOUT: must not be negative")
+	}
+
+	return problems
+}
+
+func (c *TracingConfig) validate() []string {
+	var problems []string
+	add := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	if !slices.Contains(tracingExporters, c.Exporter) {
+		add("TRACING_EXPORTER: expected one of " + strings.Join(tracingExporters, ", ") + ", got " + strconv.Quote(c.Exporter))
+	}
+	if c.Exporter == tracing.ExporterOtlp && c.Endpoint == "" {
+		add("TRACING_ENDPOINT: is required for the otlp exporter")
+	}
+	if c.ServiceName == "" {
+		add("TRACING_SERVICE_NAME: must not be empty")
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		add("TRACING_SAMPLE_RATIO: must be between 0 and 1")
+	}
+
+	return problems
+}
+
+func (c *AuthConfig) validate() []string {
+	var problems []string
+	add := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	if c.JwtSecret != "" && c.JwksFile != "" {
+		add("AUTH_JWT_SECRET, AUTH_JWKS_FILE: only one of them can be set")
+	}
+	if c.JwtSecret != "" && len(c.JwtSecret) < minJwtSecretLength {
+		add("AUTH_JWT_SECRET: must be at least " + strconv.Itoa(minJwtSecretLength) + " bytes long")
+	}
+
+	return problems
+}
+
+func (c *RateLimitConfig) validate() []string {
+	var problems []string
+	add := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	if !slices.Contains(rateLimitStores, c.Store) {
+		add("RATE_LIMIT_STORE: expected one of " + strings.Join(rateLimitStores, ", ") + ", got " + strconv.Quote(c.Store))
+	}
+	rule := func(name string, rule RateLimitRule) {
+		if rule.PerMinute <= 0 {
+			add("RATE_LIMIT_" + name + "_PER_MINUTE: must be positive")
+		}
+		if rule.Burst < 1 {
+			add("RATE_LIMIT_" + name + "_BURST: must be at least 1")
+		}
+	}
+	rule("CREATE", c.Create)
+	rule("READ", c.Read)
+
+	return problems
+}
+
+// Limit converts the rule to the token bucket of the rate limiter.
+func (r RateLimitRule) Limit() models.RateLimit {
+	return models.RateLimit{Rate: r.PerMinute / 60, Burst: int(r.Burst)}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Load applies defaults", func(t *testing.T) {
+		config, err := load(lookupFrom(map[string]string{"DB_CONNECTION_STRING": "postgres://localhost/db"}))
+		require.NoError(t, err)
+		assert.Equal(t, "8080", config.Server.Port)
+		assert.Equal(t, "info", config.Logging.Level)
+		assert.Equal(t, 24*time.Hour, config.Idempotency.Ttl)
+		assert.Equal(t, 10*time.Second, config.Enrichment.Timeout)
+		assert.Equal(t, 30*time.Second, config.Database.ConnectTimeout)
+		assert.Equal(t, 5*time.Second, config.Server.ShutdownDelay)
+		assert.Equal(t, "https://api.agify.io/?name=", config.Enrichment.AgeUrl)
+		assert.True(t, config.Auth.Enabled)
+		assert.Equal(t, RateLimitStoreMemory, config.RateLimit.Store)
+		assert.Equal(t, models.RateLimit{Rate: 1, Burst: 10}, config.RateLimit.Create.Limit())
+		assert.False(t, config.IsProduction())
+	})
+
+	t.Run("Load reads environment", func(t *testing.T) {
+		config, err := load(lookupFrom(map[string]string{
+			"ENV":                          "production",
+			"DB_CONNECTION_STRING":         "postgres://localhost/db",
+			"LOG_LEVEL":                    "DEBUG",
+			"SERVER_PORT":                  "9090",
+			"ENRICHMENT_GENDER_PROVIDERS":  "rules, genderize",
+			"ENRICHMENT_PROVIDER_WEIGHTS":  "rules=2",
+			"ENRICHMENT_LOCALIZED":         "true",
+			"ENRICHMENT_TIMEOUT":           "3s",
+			"ENRICHMENT_MIN_CONFIDENCE":    "0.8",
+			"ENRICHMENT_COUNTRY_PROVIDERS": "",
+			"LOG_REDACTION":                "name=hash, age=keep",
+			"LOG_DEBUG_SENSITIVE":          "true",
+			"AUTH_ENABLED":                 "false",
+			"AUTH_JWKS_FILE":               "/etc/person-api/jwks.json",
+			"AUTH_JWT_ROLES_CLAIM":         "realm_access.roles",
+			"RATE_LIMIT_STORE":             "postgres",
+			"RATE_LIMIT_READ_PER_MINUTE":   "120",
+			"RATE_LIMIT_READ_BURST":        "20",
+		}))
+		require.NoError(t, err)
+		assert.True(t, config.IsProduction())
+		assert.Equal(t, "debug", config.Logging.Level)
+		assert.Equal(t, "9090", config.Server.Port)
+		assert.Equal(t, []string{"rules", "genderize"}, config.Enrichment.GenderProviders)
+		assert.Empty(t, config.Enrichment.CountryProviders)
+		assert.Equal(t, map[string]float64{"rules": 2}, config.Enrichment.Weights)
+		assert.True(t, config.Enrichment.Localized)
+		assert.Equal(t, 3*time.Second, config.Enrichment.Timeout)
+		assert.Equal(t, 0.8, config.Enrichment.MinConfidence)
+		assert.Equal(t, map[string]string{"name": "hash", "age": "keep"}, config.Logging.Redaction)
+		assert.True(t, config.Logging.DebugSensitive)
+		assert.False(t, config.Auth.Enabled)
+		assert.Equal(t, "/etc/person-api/jwks.json", config.Auth.JwksFile)
+		assert.Equal(t, "realm_access.roles", config.Auth.JwtRolesClaim)
+		assert.Equal(t, RateLimitStorePostgres, config.RateLimit.Store)
+		assert.Equal(t, RateLimitRule{PerMinute: 120, Burst: 20}, config.RateLimit.Read)
+	})
+
+	t.Run("Environment overrides config file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+server:
+  port: "7070"
+database:
+  connection_string: postgres://file/db
+enrichment:
+  local_mode: fallback
+  timeout: 5s
+  weights:
+    genderize: 0.5
+`), 0o600))
+
+		config, err := load(lookupFrom(map[string]string{
+			"CONFIG_FILE": path,
+			"SERVER_PORT": "9090",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "9090", config.Server.Port)
+		assert.Equal(t, "postgres://file/db", config.Database.ConnectionString)
+		assert.Equal(t, "fallback", config.Enrichment.LocalMode)
+		assert.Equal(t, 5*time.Second, config.Enrichment.Timeout)
+		assert.Equal(t, map[string]float64{"genderize": 0.5}, config.Enrichment.Weights)
+	})
+
+	t.Run("Load rejects unknown config file keys", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("servr:\n  port: \"7070\"\n"), 0o600))
+
+		_, err := load(lookupFrom(map[string]string{"CONFIG_FILE": path}))
+		assert.ErrorContains(t, err, custom_errors.ErrConfigLoading.Message)
+	})
+
+	t.Run("Load validates pool settings", func(t *testing.T) {
+		_, err := load(lookupFrom(map[string]string{
+			"DB_CONNECTION_STRING": "postgres://localhost/db",
+			"DB_MAX_CONNS":         "4",
+			"DB_MIN_CONNS":         "8",
+			"DB_CONNECT_TIMEOUT":   "0s",
+		}))
+
+		var configErr *custom_errors.ConfigError
+		require.ErrorAs(t, err, &configErr)
+		assert.Equal(t, []string{
+			"DB_MIN_CONNS: must not exceed DB_MAX_CONNS",
+			"DB_CONNECT_TIMEOUT: must be positive",
+		}, configErr.Problems)
+	})
+
+	t.Run("Load reports every problem", func(t *testing.T) {
+		_, err := load(lookupFrom(map[string]string{
+			"SERVER_PORT":                 "http",
+			"LOG_LEVEL":                   "verbose",
+			"IDEMPOTENCY_TTL":             "day",
+			"AGE_URL":                     "agify.io",
+			"ENRICHMENT_STRATEGY":         "random",
+			"ENRICHMENT_AGE_PROVIDERS":    "agify,rules",
+			"ENRICHMENT_DEFAULT_COUNTRY":  "ru",
+			"ENRICHMENT_PROVIDER_WEIGHTS": "genderize",
+			"TRACING_EXPORTER":            "jaeger",
+			"TRACING_SAMPLE_RATIO":        "2",
+			"LOG_REDACTION":               "name=hide,email=drop",
+			"AUTH_JWT_SECRET":             "secret",
+			"AUTH_JWKS_FILE":              "jwks.json",
+			"RATE_LIMIT_STORE":            "redis",
+			"RATE_LIMIT_CREATE_BURST":     "0",
+		}))
+
+		var configErr *custom_errors.ConfigError
+		require.ErrorAs(t, err, &configErr)
+		assert.Equal(t, []string{
+			`IDEMPOTENCY_TTL: invalid duration "day"`,
+			`ENRICHMENT_PROVIDER_WEIGHTS: expected provider=weight pairs, got "genderize"`,
+			`SERVER_PORT: expected a port number, got "http"`,
+			`DB_CONNECTION_STRING: is required`,
+			`LOG_LEVEL: expected one of trace, debug, info, warn, error, fatal, panic, got "verbose"`,
+			`LOG_REDACTION: unknown field "email", expected name, surname, patronymic, age, gender, country`,
+			`LOG_REDACTION: expected one of keep, mask, hash, drop for "name", got "hide"`,
+			`AGE_URL: expected an http(s) URL, got "agify.io"`,
+			`ENRICHMENT_STRATEGY: expected one of fallback, vote, got "random"`,
+			`ENRICHMENT_AGE_PROVIDERS: unknown provider "rules", expected agify, local`,
+			`ENRICHMENT_DEFAULT_COUNTRY: expected an ISO 3166-1 alpha-2 code, got "ru"`,
+			`TRACING_EXPORTER: expected one of none, otlp, stdout, got "jaeger"`,
+			`TRACING_SAMPLE_RATIO: must be between 0 and 1`,
+			`AUTH_JWT_SECRET, AUTH_JWKS_FILE: only one of them can be set`,
+			`AUTH_JWT_SECRET: must be at least 32 bytes long`,
+			`RATE_LIMIT_STORE: expected one of memory, postgres, got "redis"`,
+			`RATE_LIMIT_CREATE_BURST: must be at least 1`,
+		}, configErr.Problems)
+	})
+}
@@ -0,0 +1,148 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type ApiKeyDriver struct {
+	adapter Adapter
+}
+
+func NewApiKeyDriver(adapter Adapter) *ApiKeyDriver {
+	log.Debug().Msg("Initializing ApiKeyDriver")
+	return &ApiKeyDriver{adapter: adapter}
+}
+
+// CreateApiKey stores the key and fills its id and creation time. A name that is already taken,
+// even by a revoked key, results in ErrApiKeyNameTaken.
+func (d *ApiKeyDriver) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) error {
+	log.Ctx(ctx).Debug().
+		Str("api_key_name", apiKey.Name).
+		Str("api_key_prefix", apiKey.Prefix).
+		Strs("roles", apiKey.Roles).
+		Msg("Creating api key in database")
+
+	err := d.adapter.QueryRow(ctx, queryCreateApiKey, apiKey.Name, apiKey.Prefix, apiKey.KeyHash, apiKey.Roles).Scan(
+		&apiKey.Id,
+		&apiKey.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Ctx(ctx).Warn().
+			Str("api_key_name", apiKey.Name).
+			Msg(custom_errors.ErrApiKeyNameTaken.Message)
+		return custom_errors.ErrApiKeyNameTaken
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("api_key_name", apiKey.Name).
+			Msg(custom_errors.ErrCreateApiKey.Message)
+		return custom_errors.ErrCreateApiKey
+	}
+
+	log.Ctx(ctx).Info().
+		Int64("api_key_id", apiKey.Id).
+		Str("api_key_name", apiKey.Name).
+		Msg("Api key created in database")
+
+	return nil
+}
+
+// GetApiKeyByHash returns nil without an error when no key has the hash.
+func (d *ApiKeyDriver) GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	log.Ctx(ctx).Debug().Msg("Fetching api key by hash from database")
+
+	var apiKey models.ApiKey
+	err := d.adapter.QueryRow(ctx, queryGetApiKeyByHash, keyHash).Scan(
+		&apiKey.Id,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.KeyHash,
+		&apiKey.Roles,
+		&apiKey.CreatedAt,
+		&apiKey.RevokedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Ctx(ctx).Debug().Msg("Api key not found")
+		return nil, nil
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrGetApiKey.Message)
+		return nil, custom_errors.ErrGetApiKey
+	}
+
+	return &apiKey, nil
+}
+
+func (d *ApiKeyDriver) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	log.Ctx(ctx).Debug().Msg("Fetching api keys from database")
+
+	rows, err := d.adapter.Query(ctx, queryListApiKeys)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrListApiKeys.Message)
+		return nil, custom_errors.ErrListApiKeys
+	}
+	defer rows.Close()
+
+	apiKeys := make([]models.ApiKey, 0)
+	for rows.Next() {
+		var apiKey models.ApiKey
+
+		err = rows.Scan(
+			&apiKey.Id,
+			&apiKey.Name,
+			&apiKey.Prefix,
+			&apiKey.KeyHash,
+			&apiKey.Roles,
+			&apiKey.CreatedAt,
+			&apiKey.RevokedAt,
+		)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Msg(custom_errors.ErrScanRow.Message)
+			return nil, custom_errors.ErrScanRow
+		}
+
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	log.Ctx(ctx).Debug().
+		Int("api_keys_count", len(apiKeys)).
+		Msg("Successfully fetched api keys from database")
+
+	return apiKeys, nil
+}
+
+// RevokeApiKey reports whether an active key with the name existed.
+func (d *ApiKeyDriver) RevokeApiKey(ctx context.Context, name string) (bool, error) {
+	log.Ctx(ctx).Debug().
+		Str("api_key_name", name).
+		Msg("Revoking api key in database")
+
+	tag, err := d.adapter.Exec(ctx, queryRevokeApiKey, name)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("api_key_name", name).
+			Msg(custom_errors.ErrRevokeApiKey.Message)
+		return false, custom_errors.ErrRevokeApiKey
+	}
+
+	revoked := tag.RowsAffected() == 1
+	log.Ctx(ctx).Debug().
+		Str("api_key_name", name).
+		Bool("revoked", revoked).
+		Msg("Api key revocation finished")
+
+	return revoked, nil
+}
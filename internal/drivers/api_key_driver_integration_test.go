@@ -0,0 +1,64 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestApiKeyLifecycle(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewApiKeyDriver(pool)
+	ctx := context.Background()
+
+	apiKey := &models.ApiKey{Name: "billing", Prefix: "em_abcd", KeyHash: "hash", Roles: []string{models.RoleEditor}}
+	require.NoError(t, driver.CreateApiKey(ctx, apiKey))
+	assert.NotZero(t, apiKey.Id)
+	assert.False(t, apiKey.CreatedAt.IsZero())
+
+	t.Run("CreateApiKey with taken name", func(t *testing.T) {
+		err := driver.CreateApiKey(ctx, &models.ApiKey{Name: "billing", Prefix: "em_efgh", KeyHash: "other hash"})
+		assert.ErrorIs(t, err, custom_errors.ErrApiKeyNameTaken)
+	})
+
+	t.Run("GetApiKeyByHash", func(t *testing.T) {
+		stored, err := driver.GetApiKeyByHash(ctx, "hash")
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, "billing", stored.Name)
+		assert.Equal(t, []string{models.RoleEditor}, stored.Roles)
+		assert.False(t, stored.IsRevoked())
+	})
+
+	t.Run("GetApiKeyByHash with unknown hash", func(t *testing.T) {
+		stored, err := driver.GetApiKeyByHash(ctx, "unknown")
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("ListApiKeys", func(t *testing.T) {
+		apiKeys, err := driver.ListApiKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, apiKeys, 1)
+		assert.Equal(t, "em_abcd", apiKeys[0].Prefix)
+	})
+
+	t.Run("RevokeApiKey", func(t *testing.T) {
+		revoked, err := driver.RevokeApiKey(ctx, "billing")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		stored, err := driver.GetApiKeyByHash(ctx, "hash")
+		require.NoError(t, err)
+		assert.True(t, stored.IsRevoked())
+
+		revoked, err = driver.RevokeApiKey(ctx, "billing")
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
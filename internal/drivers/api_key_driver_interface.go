@@ -0,0 +1,13 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+)
+
+type ApiKeyDriverInterface interface {
+	CreateApiKey(ctx context.Context, apiKey *models.ApiKey) error
+	GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error)
+	ListApiKeys(ctx context.Context) ([]models.ApiKey, error)
+	RevokeApiKey(ctx context.Context, name string) (bool, error)
+}
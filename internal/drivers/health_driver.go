@@ -0,0 +1,32 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+type HealthDriver struct {
+	pool     *pgxpool.Pool
+	migrator *Migrator
+}
+
+func NewHealthDriver(pool *pgxpool.Pool, migrator *Migrator) *HealthDriver {
+	log.Debug().Msg("Initializing HealthDriver")
+	return &HealthDriver{pool: pool, migrator: migrator}
+}
+
+func (d *HealthDriver) Ping(ctx context.Context) error {
+	if err := d.pool.Ping(ctx); err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Msg(custom_errors.ErrPingDatabase.Message)
+		return custom_errors.ErrPingDatabase
+	}
+	return nil
+}
+
+func (d *HealthDriver) HasPendingMigrations(ctx context.Context) (bool, error) {
+	return d.migrator.HasPending(ctx)
+}
@@ -0,0 +1,8 @@
+package drivers
+
+import "context"
+
+type HealthDriverInterface interface {
+	Ping(ctx context.Context) error
+	HasPendingMigrations(ctx context.Context) (bool, error)
+}
@@ -0,0 +1,137 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+type IdempotencyDriver struct {
+	adapter Adapter
+}
+
+func NewIdempotencyDriver(adapter Adapter) *IdempotencyDriver {
+	log.Debug().Msg("Initializing IdempotencyDriver")
+	return &IdempotencyDriver{adapter: adapter}
+}
+
+func (d *IdempotencyDriver) ReserveIdempotencyKey(ctx context.Context, principal, key, requestHash string, expiresAt time.Time) (bool, error) {
+	log.Ctx(ctx).Debug().
+		Str("idempotency_key", key).
+		Time("expires_at", expiresAt).
+		Msg("Reserving idempotency key")
+
+	tag, err := d.adapter.Exec(ctx, queryReserveIdempotencyKey, principal, key, requestHash, expiresAt)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("idempotency_key", key).
+			Msg(custom_errors.ErrReserveIdempotencyKey.Message)
+		return false, custom_errors.ErrReserveIdempotencyKey
+	}
+
+	reserved := tag.RowsAffected() == 1
+	log.Ctx(ctx).Debug().
+		Str("idempotency_key", key).
+		Bool("reserved", reserved).
+		Msg("Idempotency key reservation finished")
+
+	return reserved, nil
+}
+
+func (d *IdempotencyDriver) GetIdempotencyKey(ctx context.Context, principal, key string) (*models.IdempotencyRecord, error) {
+	log.Ctx(ctx).Debug().
+		Str("idempotency_key", key).
+		Msg("Fetching idempotency key from database")
+
+	record := models.IdempotencyRecord{Principal: principal, Key: key}
+
+	err := d.adapter.QueryRow(ctx, queryGetIdempotencyKey, principal, key).Scan(
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Ctx(ctx).Debug().
+			Str("idempotency_key", key).
+			Msg("Idempotency key not found or expired")
+		return nil, nil
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("idempotency_key", key).
+			Msg(custom_errors.ErrGetIdempotencyKey.Message)
+		return nil, custom_errors.ErrGetIdempotencyKey
+	}
+
+	return &record, nil
+}
+
+func (d *IdempotencyDriver) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	log.Ctx(ctx).Debug().
+		Str("idempotency_key", record.Key).
+		Int("status_code", record.StatusCode).
+		Msg("Storing response for idempotency key")
+
+	_, err := d.adapter.Exec(
+		ctx,
+		queryCompleteIdempotencyKey,
+		record.Principal,
+		record.Key,
+		record.StatusCode,
+		record.ContentType,
+		record.ResponseBody,
+	)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("idempotency_key", record.Key).
+			Msg(custom_errors.ErrCompleteIdempotencyKey.Message)
+		return custom_errors.ErrCompleteIdempotencyKey
+	}
+
+	return nil
+}
+
+func (d *IdempotencyDriver) DeleteIdempotencyKey(ctx context.Context, principal, key string) error {
+	log.Ctx(ctx).Debug().
+		Str("idempotency_key", key).
+		Msg("Deleting idempotency key")
+
+	_, err := d.adapter.Exec(ctx, queryDeleteIdempotencyKey, principal, key)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("idempotency_key", key).
+			Msg(custom_errors.ErrDeleteIdempotencyKey.Message)
+		return custom_errors.ErrDeleteIdempotencyKey
+	}
+
+	return nil
+}
+
+func (d *IdempotencyDriver) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	log.Ctx(ctx).Debug().Msg("Deleting expired idempotency keys")
+
+	tag, err := d.adapter.Exec(ctx, queryDeleteExpiredIdempotencyKeys)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrDeleteIdempotencyKey.Message)
+		return 0, custom_errors.ErrDeleteIdempotencyKey
+	}
+
+	log.Ctx(ctx).Debug().
+		Int64("deleted_count", tag.RowsAffected()).
+		Msg("Expired idempotency keys deleted")
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,85 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeyLifecycle(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewIdempotencyDriver(pool)
+	ctx := context.Background()
+
+	principal := "api_key:ci"
+	key := "key"
+	requestHash := "hash"
+
+	reserved, err := driver.ReserveIdempotencyKey(ctx, principal, key, requestHash, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	t.Run("ReserveIdempotencyKey with active key", func(t *testing.T) {
+		reserved, err := driver.ReserveIdempotencyKey(ctx, principal, key, "other hash", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.False(t, reserved)
+	})
+
+	t.Run("GetIdempotencyKey with key in progress", func(t *testing.T) {
+		record, err := driver.GetIdempotencyKey(ctx, principal, key)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.Equal(t, requestHash, record.RequestHash)
+		assert.False(t, record.IsCompleted())
+	})
+
+	t.Run("GetIdempotencyKey with completed key", func(t *testing.T) {
+		err := driver.CompleteIdempotencyKey(ctx, &models.IdempotencyRecord{
+			Principal:    principal,
+			Key:          key,
+			StatusCode:   201,
+			ContentType:  "application/json",
+			ResponseBody: []byte(`{"id":"id"}`),
+		})
+		require.NoError(t, err)
+
+		record, err := driver.GetIdempotencyKey(ctx, principal, key)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.True(t, record.IsCompleted())
+		assert.Equal(t, 201, record.StatusCode)
+		assert.Equal(t, []byte(`{"id":"id"}`), record.ResponseBody)
+	})
+
+	t.Run("ReserveIdempotencyKey with expired key", func(t *testing.T) {
+		expiredKey := "expired"
+		reserved, err := driver.ReserveIdempotencyKey(ctx, principal, expiredKey, requestHash, time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		record, err := driver.GetIdempotencyKey(ctx, principal, expiredKey)
+		require.NoError(t, err)
+		assert.Nil(t, record)
+
+		reserved, err = driver.ReserveIdempotencyKey(ctx, principal, expiredKey, requestHash, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("ReserveIdempotencyKey with key of another principal", func(t *testing.T) {
+		reserved, err := driver.ReserveIdempotencyKey(ctx, "jwt:other", key, "other hash", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, reserved)
+
+		record, err := driver.GetIdempotencyKey(ctx, "jwt:other", key)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.Equal(t, "other hash", record.RequestHash)
+		assert.False(t, record.IsCompleted())
+	})
+}
@@ -0,0 +1,15 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"time"
+)
+
+type IdempotencyDriverInterface interface {
+	ReserveIdempotencyKey(ctx context.Context, principal, key, requestHash string, expiresAt time.Time) (bool, error)
+	GetIdempotencyKey(ctx context.Context, principal, key string) (*models.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteIdempotencyKey(ctx context.Context, principal, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
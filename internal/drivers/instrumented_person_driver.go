@@ -0,0 +1,99 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/metrics"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/tracing"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"time"
+)
+
+// InstrumentedPersonDriver records a span and the latency of every call to the wrapped driver.
+type InstrumentedPersonDriver struct {
+	driver PersonDriverInterface
+}
+
+func NewInstrumentedPersonDriver(driver PersonDriverInterface) *InstrumentedPersonDriver {
+	log.Debug().Msg("Initializing InstrumentedPersonDriver")
+	return &InstrumentedPersonDriver{driver: driver}
+}
+
+// start opens the span of operation, the returned function ends it and observes the latency.
+func (d *InstrumentedPersonDriver) start(ctx context.Context, spanName string, operation string) (context.Context, func(err error)) {
+	startedAt := time.Now()
+	ctx, span := tracing.Start(ctx, spanName,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", operation),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveQuery(operation, startedAt, err)
+		tracing.End(span, err)
+	}
+}
+
+func (d *InstrumentedPersonDriver) CreatePerson(ctx context.Context, person *models.Person, results []models.ProviderResult) error {
+	ctx, finish := d.start(ctx, "PersonDriver.CreatePerson", "create_person")
+	err := d.driver.CreatePerson(ctx, person, results)
+	finish(err)
+	return err
+}
+
+func (d *InstrumentedPersonDriver) UpdatePerson(ctx context.Context, personDto dtos.PersonDto) (*models.Person, error) {
+	ctx, finish := d.start(ctx, "PersonDriver.UpdatePerson", "update_person")
+	person, err := d.driver.UpdatePerson(ctx, personDto)
+	finish(err)
+	return person, err
+}
+
+func (d *InstrumentedPersonDriver) ApplyEnrichment(ctx context.Context, person *models.Person, changes []models.EnrichmentChange, results []models.ProviderResult) error {
+	ctx, finish := d.start(ctx, "PersonDriver.ApplyEnrichment", "apply_enrichment")
+	err := d.driver.ApplyEnrichment(ctx, person, changes, results)
+	finish(err)
+	return err
+}
+
+func (d *InstrumentedPersonDriver) DeletePerson(ctx context.Context, personId pgtype.UUID) error {
+	ctx, finish := d.start(ctx, "PersonDriver.DeletePerson", "delete_person")
+	err := d.driver.DeletePerson(ctx, personId)
+	finish(err)
+	return err
+}
+
+func (d *InstrumentedPersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) ([]models.Person, error) {
+	ctx, finish := d.start(ctx, "PersonDriver.GetPersons", "get_persons")
+	persons, err := d.driver.GetPersons(ctx, getPersonDto)
+	finish(err)
+	return persons, err
+}
+
+func (d *InstrumentedPersonDriver) CountPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (int64, error) {
+	ctx, finish := d.start(ctx, "PersonDriver.CountPersons", "count_persons")
+	count, err := d.driver.CountPersons(ctx, getPersonDto)
+	finish(err)
+	return count, err
+}
+
+func (d *InstrumentedPersonDriver) GetPersonById(ctx context.Context, id pgtype.UUID) (*models.Person, error) {
+	ctx, finish := d.start(ctx, "PersonDriver.GetPersonById", "get_person_by_id")
+	person, err := d.driver.GetPersonById(ctx, id)
+	finish(err)
+	return person, err
+}
+
+func (d *InstrumentedPersonDriver) GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]models.ProviderResult, error) {
+	ctx, finish := d.start(ctx, "PersonDriver.GetEnrichmentResults", "get_enrichment_results")
+	results, err := d.driver.GetEnrichmentResults(ctx, personId)
+	finish(err)
+	return results, err
+}
+
+func (d *InstrumentedPersonDriver) GetNameStatistics(ctx context.Context) ([]models.NameStatistics, error) {
+	ctx, finish := d.start(ctx, "PersonDriver.GetNameStatistics", "get_name_statistics")
+	statistics, err := d.driver.GetNameStatistics(ctx)
+	finish(err)
+	return statistics, err
+}
@@ -0,0 +1,71 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"time"
+)
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryRateLimitDriver keeps the token buckets in the process. Every replica limits the
+// requests it serves on its own.
+type MemoryRateLimitDriver struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+func NewMemoryRateLimitDriver() *MemoryRateLimitDriver {
+	log.Debug().Msg("Initializing MemoryRateLimitDriver")
+	return &MemoryRateLimitDriver{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+func (d *MemoryRateLimitDriver) TakeToken(_ context.Context, key string, limit models.RateLimit) (float64, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	bucket, ok := d.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+		d.buckets[key] = bucket
+	}
+
+	if elapsed := now.Sub(bucket.updatedAt); elapsed > 0 {
+		bucket.tokens = min(float64(limit.Burst), bucket.tokens+elapsed.Seconds()*limit.Rate)
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens < 1 {
+		return bucket.tokens, false, nil
+	}
+	bucket.tokens--
+	return bucket.tokens, true, nil
+}
+
+func (d *MemoryRateLimitDriver) DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deleted int64
+	threshold := d.now().Add(-idle)
+	for key, bucket := range d.buckets {
+		if bucket.updatedAt.Before(threshold) {
+			delete(d.buckets, key)
+			deleted++
+		}
+	}
+
+	log.Ctx(ctx).Debug().
+		Int64("deleted_count", deleted).
+		Int("buckets_count", len(d.buckets)).
+		Msg("Idle rate limit buckets deleted from memory")
+
+	return deleted, nil
+}
@@ -0,0 +1,61 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitDriver(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	driver := NewMemoryRateLimitDriver()
+	driver.now = func() time.Time { return now }
+	limit := models.RateLimit{Rate: 1, Burst: 2}
+
+	t.Run("Burst is allowed", func(t *testing.T) {
+		tokens, allowed, err := driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 1.0, tokens)
+
+		_, allowed, _ = driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		assert.True(t, allowed)
+	})
+
+	t.Run("Empty bucket rejects", func(t *testing.T) {
+		tokens, allowed, err := driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, 0.0, tokens)
+	})
+
+	t.Run("Other keys have own buckets", func(t *testing.T) {
+		_, allowed, _ := driver.TakeToken(ctx, "create:ip:10.0.0.2", limit)
+		assert.True(t, allowed)
+	})
+
+	t.Run("Bucket refills over time", func(t *testing.T) {
+		now = now.Add(1500 * time.Millisecond)
+
+		tokens, allowed, err := driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.InDelta(t, 0.5, tokens, 1e-9)
+
+		now = now.Add(time.Hour)
+		tokens, _, _ = driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		assert.Equal(t, 1.0, tokens)
+	})
+
+	t.Run("DeleteIdleBuckets", func(t *testing.T) {
+		deleted, err := driver.DeleteIdleBuckets(ctx, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Len(t, driver.buckets, 1)
+	})
+}
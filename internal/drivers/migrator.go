@@ -0,0 +1,144 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/migrations"
+	"errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/rs/zerolog/log"
+	"path/filepath"
+	"strings"
+)
+
+// Migrator applies the embedded migrations. Up and Down hold a Postgres advisory lock, so
+// replicas starting together run them one at a time.
+type Migrator struct {
+	db       *sql.DB
+	provider *goose.Provider
+}
+
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	log.Debug().Msg("Initializing Migrator")
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrInitMigrations.Message)
+		return nil, custom_errors.ErrInitMigrations
+	}
+
+	db := stdlib.OpenDBFromPool(pool)
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		db.Close()
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrInitMigrations.Message)
+		return nil, custom_errors.ErrInitMigrations
+	}
+
+	return &Migrator{db: db, provider: provider}, nil
+}
+
+// Close releases the database/sql handle, the pool itself stays open.
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Up applies all pending migrations and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]models.MigrationStatus, error) {
+	log.Info().Msg("Applying pending migrations")
+
+	results, err := m.provider.Up(ctx)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrApplyMigrations.Message)
+		return nil, custom_errors.ErrApplyMigrations
+	}
+
+	applied := make([]models.MigrationStatus, 0, len(results))
+	for _, result := range results {
+		status := newMigrationStatus(result.Source)
+		status.Applied = true
+		applied = append(applied, status)
+
+		log.Info().
+			Int64("version", status.Version).
+			Str("name", status.Name).
+			Dur("duration", result.Duration).
+			Msg("Migration applied")
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the latest applied migration.
+func (m *Migrator) Down(ctx context.Context) (*models.MigrationStatus, error) {
+	log.Info().Msg("Rolling back the latest migration")
+
+	result, err := m.provider.Down(ctx)
+	if err != nil {
+		if errors.Is(err, goose.ErrNoNextVersion) {
+			log.Warn().Msg(custom_errors.ErrNoMigrationToRollBack.Message)
+			return nil, custom_errors.ErrNoMigrationToRollBack
+		}
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrApplyMigrations.Message)
+		return nil, custom_errors.ErrApplyMigrations
+	}
+
+	status := newMigrationStatus(result.Source)
+	log.Info().
+		Int64("version", status.Version).
+		Str("name", status.Name).
+		Msg("Migration rolled back")
+
+	return &status, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]models.MigrationStatus, error) {
+	results, err := m.provider.Status(ctx)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrGetMigrationStatus.Message)
+		return nil, custom_errors.ErrGetMigrationStatus
+	}
+
+	statuses := make([]models.MigrationStatus, 0, len(results))
+	for _, result := range results {
+		status := newMigrationStatus(result.Source)
+		status.Applied = result.State == goose.StateApplied
+		status.AppliedAt = result.AppliedAt
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) HasPending(ctx context.Context) (bool, error) {
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrGetMigrationStatus.Message)
+		return false, custom_errors.ErrGetMigrationStatus
+	}
+	return pending, nil
+}
+
+func newMigrationStatus(source *goose.Source) models.MigrationStatus {
+	return models.MigrationStatus{
+		Version: source.Version,
+		Name:    strings.TrimSuffix(filepath.Base(source.Path), ".sql"),
+	}
+}
@@ -0,0 +1,65 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMigrator(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	migrator, err := NewMigrator(pool)
+	require.NoError(t, err)
+	defer migrator.Close()
+
+	t.Run("Status after setup", func(t *testing.T) {
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, statuses)
+		for _, status := range statuses {
+			assert.True(t, status.Applied, status.Name)
+		}
+
+		pending, err := migrator.HasPending(ctx)
+		require.NoError(t, err)
+		assert.False(t, pending)
+	})
+
+	t.Run("Up without pending migrations", func(t *testing.T) {
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("Down rolls back every migration", func(t *testing.T) {
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+
+		for i := len(statuses) - 1; i >= 0; i-- {
+			rolledBack, err := migrator.Down(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, statuses[i].Version, rolledBack.Version)
+		}
+
+		_, err = migrator.Down(ctx)
+		assert.Equal(t, custom_errors.ErrNoMigrationToRollBack, err)
+
+		pending, err := migrator.HasPending(ctx)
+		require.NoError(t, err)
+		assert.True(t, pending)
+	})
+
+	t.Run("Up applies every migration again", func(t *testing.T) {
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		assert.Len(t, applied, len(statuses))
+	})
+}
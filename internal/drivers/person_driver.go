@@ -5,6 +5,7 @@ import (
 	"effective-mobile/internal/dtos"
 	"effective-mobile/internal/models"
 	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/redact"
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
@@ -22,17 +23,27 @@ func NewPersonDriver(adapter Adapter) *PersonDriver {
 	return &PersonDriver{adapter: adapter}
 }
 
-func (d *PersonDriver) CreatePerson(ctx context.Context, person *models.Person) error {
-	log.Info().
+func (d *PersonDriver) CreatePerson(ctx context.Context, person *models.Person, results []models.ProviderResult) error {
+	log.Ctx(ctx).Info().
 		Str("person_id", person.Id.String()).
-		Str("name", person.Name).
-		Str("surname", person.Surname).
-		Uint32("age", person.Age).
-		Str("gender", string(person.Gender)).
-		Str("country", person.Country).
+		Func(redact.Str(redact.FieldName, person.Name)).
+		Func(redact.Str(redact.FieldSurname, person.Surname)).
+		Func(redact.Uint32(redact.FieldAge, person.Age)).
+		Func(redact.Str(redact.FieldGender, string(person.Gender))).
+		Func(redact.Str(redact.FieldCountry, person.Country)).
 		Msg("Creating person in database")
 
-	_, err := d.adapter.Exec(
+	tx, err := d.adapter.Begin(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("person_id", person.Id.String()).
+			Msg(custom_errors.ErrCreatePerson.Message)
+		return custom_errors.ErrCreatePerson
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(
 		ctx,
 		queryCreatePerson,
 		person.Id,
@@ -42,27 +53,46 @@ func (d *PersonDriver) CreatePerson(ctx context.Context, person *models.Person)
 		person.Age,
 		person.Gender,
 		person.Country,
+		person.AgeSource.Source,
+		person.AgeSource.UpdatedAt,
+		person.GenderSource.Source,
+		person.GenderSource.UpdatedAt,
+		person.CountrySource.Source,
+		person.CountrySource.UpdatedAt,
 	)
 
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", person.Id.String()).
-			Str("name", person.Name).
-			Str("surname", person.Surname).
+			Func(redact.Str(redact.FieldName, person.Name)).
+			Func(redact.Str(redact.FieldSurname, person.Surname)).
 			Msg(custom_errors.ErrCreatePerson.Message)
 		return custom_errors.ErrCreatePerson
 	}
 
-	log.Debug().
+	if err = insertEnrichmentResults(ctx, tx, person.Id, results); err != nil {
+		return custom_errors.ErrCreatePerson
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("person_id", person.Id.String()).
+			Msg(custom_errors.ErrCreatePerson.Message)
+		return custom_errors.ErrCreatePerson
+	}
+
+	log.Ctx(ctx).Debug().
 		Str("person_id", person.Id.String()).
+		Int("results_count", len(results)).
 		Msg("Person successfully created in database")
 
 	return nil
 }
 
 func (d *PersonDriver) UpdatePerson(ctx context.Context, personDto dtos.PersonDto) (*models.Person, error) {
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personDto.Id.String()).
 		Msg("Updating person in database")
 
@@ -71,13 +101,13 @@ func (d *PersonDriver) UpdatePerson(ctx context.Context, personDto dtos.PersonDt
 	setValues, args, argCnt := setArgumentsForUpdate(personDto)
 
 	if len(setValues) == 0 {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Str("person_id", personDto.Id.String()).
 			Msg(custom_errors.ErrNoFieldsToUpdate.Message)
 		return nil, custom_errors.ErrNoFieldsToUpdate
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", personDto.Id.String()).
 		Int("fields_to_update", len(setValues)).
 		Msg("Prepared update query arguments")
@@ -89,58 +119,154 @@ func (d *PersonDriver) UpdatePerson(ctx context.Context, personDto dtos.PersonDt
 		argCnt,
 	)
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", personDto.Id.String()).
-		Str("query", query).
+		Func(redact.SensitiveStr("query", query)).
 		Msg("Executing update query")
 
 	_, err := d.adapter.Exec(ctx, query, args...)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personDto.Id.String()).
-			Str("query", query).
+			Func(redact.SensitiveStr("query", query)).
 			Msg(custom_errors.ErrUpdatePerson.Message)
 		return nil, custom_errors.ErrUpdatePerson
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", personDto.Id.String()).
 		Msg("Fetching updated person")
 
 	person, err := d.GetPersonById(ctx, personDto.Id)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personDto.Id.String()).
 			Msg("Failed to fetch updated person")
 		return nil, err
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", personDto.Id.String()).
-		Str("name", person.Name).
-		Str("surname", person.Surname).
+		Func(redact.Str(redact.FieldName, person.Name)).
+		Func(redact.Str(redact.FieldSurname, person.Surname)).
 		Msg("Successfully updated person in database")
 
 	return person, nil
 }
 
+// ApplyEnrichment stores the enriched values of person together with the audit trail. Fields
+// changed manually since person was read keep their value: person is updated with the stored
+// values and only the changes that were applied are recorded.
+func (d *PersonDriver) ApplyEnrichment(ctx context.Context, person *models.Person, changes []models.EnrichmentChange, results []models.ProviderResult) error {
+	log.Ctx(ctx).Info().
+		Str("person_id", person.Id.String()).
+		Int("changes_count", len(changes)).
+		Int("results_count", len(results)).
+		Msg("Applying enrichment to person in database")
+
+	tx, err := d.adapter.Begin(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("person_id", person.Id.String()).
+			Msg(custom_errors.ErrApplyEnrichment.Message)
+		return custom_errors.ErrApplyEnrichment
+	}
+	defer tx.Rollback(ctx)
+
+	// Only the audit trail is written when the values did not change.
+	if len(changes) > 0 {
+		intended := *person
+		err = tx.QueryRow(
+			ctx,
+			queryApplyEnrichment,
+			person.Id,
+			person.Age,
+			person.AgeSource.Source,
+			person.AgeSource.UpdatedAt,
+			person.Gender,
+			person.GenderSource.Source,
+			person.GenderSource.UpdatedAt,
+			person.Country,
+			person.CountrySource.Source,
+			person.CountrySource.UpdatedAt,
+		).Scan(
+			&person.Age,
+			&person.Gender,
+			&person.Country,
+			&person.AgeSource.Source,
+			&person.AgeSource.UpdatedAt,
+			&person.GenderSource.Source,
+			&person.GenderSource.UpdatedAt,
+			&person.CountrySource.Source,
+			&person.CountrySource.UpdatedAt,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("person_id", person.Id.String()).
+				Msg(custom_errors.ErrPersonNotFound.Message)
+			return custom_errors.ErrPersonNotFound
+		}
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("person_id", person.Id.String()).
+				Msg(custom_errors.ErrApplyEnrichment.Message)
+			return custom_errors.ErrApplyEnrichment
+		}
+
+		changes = appliedChanges(ctx, &intended, person, changes)
+	}
+
+	for _, change := range changes {
+		_, err = tx.Exec(ctx, queryCreateEnrichmentChange, person.Id, change.Field, change.OldValue, change.NewValue)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("person_id", person.Id.String()).
+				Str("field", change.Field).
+				Msg(custom_errors.ErrApplyEnrichment.Message)
+			return custom_errors.ErrApplyEnrichment
+		}
+	}
+
+	if err = insertEnrichmentResults(ctx, tx, person.Id, results); err != nil {
+		return custom_errors.ErrApplyEnrichment
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("person_id", person.Id.String()).
+			Msg(custom_errors.ErrApplyEnrichment.Message)
+		return custom_errors.ErrApplyEnrichment
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("person_id", person.Id.String()).
+		Msg("Enrichment successfully applied in database")
+
+	return nil
+}
+
 func (d *PersonDriver) DeletePerson(ctx context.Context, personId pgtype.UUID) error {
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personId.String()).
 		Msg("Deleting person from database")
 
 	_, err := d.adapter.Exec(ctx, queryDeletePerson, personId)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personId.String()).
 			Msg(custom_errors.ErrDeletePerson.Message)
 		return custom_errors.ErrDeletePerson
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", personId.String()).
 		Msg("Successfully deleted person from database")
 
@@ -148,14 +274,14 @@ func (d *PersonDriver) DeletePerson(ctx context.Context, personId pgtype.UUID) e
 }
 
 func (d *PersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) ([]models.Person, error) {
-	log.Info().Msg("Fetching persons from database with filters")
+	log.Ctx(ctx).Info().Msg("Fetching persons from database with filters")
 
 	var persons []models.Person
 	query := queryGetPersons
 
 	setValues, args, argCnt := setArgumentsForGet(getPersonDto)
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Int("filter_conditions", len(setValues)).
 		Int("arguments_count", len(args)).
 		Msg("Prepared query filters")
@@ -170,7 +296,7 @@ func (d *PersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.GetPers
 		query += fmt.Sprintf(" LIMIT $%d", argCnt)
 		args = append(args, *getPersonDto.Limit)
 		argCnt++
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Uint32("limit", *getPersonDto.Limit).
 			Msg("Applied limit to query")
 	}
@@ -179,20 +305,20 @@ func (d *PersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.GetPers
 		query += fmt.Sprintf(" OFFSET $%d", argCnt)
 		args = append(args, *getPersonDto.Offset)
 		argCnt++
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Uint32("offset", *getPersonDto.Offset).
 			Msg("Applied offset to query")
 	}
 
-	log.Debug().
-		Str("query", query).
+	log.Ctx(ctx).Debug().
+		Func(redact.SensitiveStr("query", query)).
 		Msg("Executing persons query")
 
 	rows, err := d.adapter.Query(ctx, query, args...)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("query", query).
+			Func(redact.SensitiveStr("query", query)).
 			Msg(custom_errors.ErrGetPerson.Message)
 		return nil, custom_errors.ErrGetPerson
 	}
@@ -210,9 +336,15 @@ func (d *PersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.GetPers
 			&person.Age,
 			&person.Gender,
 			&person.Country,
+			&person.AgeSource.Source,
+			&person.AgeSource.UpdatedAt,
+			&person.GenderSource.Source,
+			&person.GenderSource.UpdatedAt,
+			&person.CountrySource.Source,
+			&person.CountrySource.UpdatedAt,
 		)
 		if err != nil {
-			log.Error().
+			log.Ctx(ctx).Error().
 				Err(err).
 				Msg(custom_errors.ErrScanRow.Message)
 			return nil, custom_errors.ErrScanRow
@@ -222,15 +354,46 @@ func (d *PersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.GetPers
 		personCount++
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Int("found_count", personCount).
 		Msg("Successfully fetched persons from database")
 
 	return persons, nil
 }
 
+// CountPersons counts the persons matching the filters of getPersonDto, limit and offset
+// are ignored.
+func (d *PersonDriver) CountPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (int64, error) {
+	log.Ctx(ctx).Info().Msg("Counting persons in database with filters")
+
+	query := queryCountPersons
+	setValues, args, _ := setArgumentsForGet(getPersonDto)
+	if len(setValues) > 0 {
+		query += " WHERE " + strings.Join(setValues, " AND ")
+	}
+
+	log.Ctx(ctx).Debug().
+		Func(redact.SensitiveStr("query", query)).
+		Msg("Executing count persons query")
+
+	var count int64
+	if err := d.adapter.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Func(redact.SensitiveStr("query", query)).
+			Msg(custom_errors.ErrCountPersons.Message)
+		return 0, custom_errors.ErrCountPersons
+	}
+
+	log.Ctx(ctx).Debug().
+		Int64("count", count).
+		Msg("Successfully counted persons in database")
+
+	return count, nil
+}
+
 func (d *PersonDriver) GetPersonById(ctx context.Context, id pgtype.UUID) (*models.Person, error) {
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", id.String()).
 		Msg("Fetching person by ID from database")
 
@@ -243,34 +406,185 @@ func (d *PersonDriver) GetPersonById(ctx context.Context, id pgtype.UUID) (*mode
 		&person.Age,
 		&person.Gender,
 		&person.Country,
+		&person.AgeSource.Source,
+		&person.AgeSource.UpdatedAt,
+		&person.GenderSource.Source,
+		&person.GenderSource.UpdatedAt,
+		&person.CountrySource.Source,
+		&person.CountrySource.UpdatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", id.String()).
 			Msg(custom_errors.ErrPersonNotFound.Message)
 		return nil, custom_errors.ErrPersonNotFound
 	}
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", id.String()).
 			Msg(custom_errors.ErrGetPersonById.Message)
 		return nil, custom_errors.ErrGetPersonById
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", id.String()).
-		Str("name", person.Name).
-		Str("surname", person.Surname).
-		Uint32("age", person.Age).
-		Str("gender", string(person.Gender)).
-		Str("country", person.Country).
+		Func(redact.Str(redact.FieldName, person.Name)).
+		Func(redact.Str(redact.FieldSurname, person.Surname)).
+		Func(redact.Uint32(redact.FieldAge, person.Age)).
+		Func(redact.Str(redact.FieldGender, string(person.Gender))).
+		Func(redact.Str(redact.FieldCountry, person.Country)).
 		Msg("Successfully fetched person from database")
 
 	return &person, nil
 }
 
+func (d *PersonDriver) GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]models.ProviderResult, error) {
+	log.Ctx(ctx).Info().
+		Str("person_id", personId.String()).
+		Msg("Fetching enrichment results from database")
+
+	rows, err := d.adapter.Query(ctx, queryGetEnrichmentResults, personId)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("person_id", personId.String()).
+			Msg(custom_errors.ErrGetEnrichmentResults.Message)
+		return nil, custom_errors.ErrGetEnrichmentResults
+	}
+	defer rows.Close()
+
+	results := make([]models.ProviderResult, 0)
+	for rows.Next() {
+		var result models.ProviderResult
+
+		err = rows.Scan(
+			&result.Field,
+			&result.Provider,
+			&result.Value,
+			&result.Confidence,
+			&result.Error,
+			&result.Chosen,
+			&result.CreatedAt,
+		)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Msg(custom_errors.ErrScanRow.Message)
+			return nil, custom_errors.ErrScanRow
+		}
+
+		results = append(results, result)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("person_id", personId.String()).
+		Int("results_count", len(results)).
+		Msg("Successfully fetched enrichment results from database")
+
+	return results, nil
+}
+
+func (d *PersonDriver) GetNameStatistics(ctx context.Context) ([]models.NameStatistics, error) {
+	log.Ctx(ctx).Info().Msg("Aggregating name statistics from database")
+
+	rows, err := d.adapter.Query(ctx, queryGetNameStatistics)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrGetNameStatistics.Message)
+		return nil, custom_errors.ErrGetNameStatistics
+	}
+	defer rows.Close()
+
+	var statistics []models.NameStatistics
+	indexByName := make(map[string]int)
+	for rows.Next() {
+		stat := models.NameStatistics{Countries: make(map[string]int)}
+
+		err = rows.Scan(&stat.Name, &stat.AgeCount, &stat.MeanAge, &stat.MaleCount, &stat.FemaleCount)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Msg(custom_errors.ErrScanRow.Message)
+			return nil, custom_errors.ErrScanRow
+		}
+
+		indexByName[stat.Name] = len(statistics)
+		statistics = append(statistics, stat)
+	}
+	if err = rows.Err(); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrGetNameStatistics.Message)
+		return nil, custom_errors.ErrGetNameStatistics
+	}
+
+	countryRows, err := d.adapter.Query(ctx, queryGetNameCountries)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrGetNameStatistics.Message)
+		return nil, custom_errors.ErrGetNameStatistics
+	}
+	defer countryRows.Close()
+
+	for countryRows.Next() {
+		var name, country string
+		var count int
+
+		if err = countryRows.Scan(&name, &country, &count); err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Msg(custom_errors.ErrScanRow.Message)
+			return nil, custom_errors.ErrScanRow
+		}
+
+		if i, ok := indexByName[name]; ok {
+			statistics[i].Countries[country] = count
+		}
+	}
+	if err = countryRows.Err(); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrGetNameStatistics.Message)
+		return nil, custom_errors.ErrGetNameStatistics
+	}
+
+	log.Ctx(ctx).Debug().
+		Int("names_count", len(statistics)).
+		Msg("Successfully aggregated name statistics")
+
+	return statistics, nil
+}
+
+func insertEnrichmentResults(ctx context.Context, tx pgx.Tx, personId pgtype.UUID, results []models.ProviderResult) error {
+	for _, result := range results {
+		_, err := tx.Exec(
+			ctx,
+			queryCreateEnrichmentResult,
+			personId,
+			result.Field,
+			result.Provider,
+			result.Value,
+			result.Confidence,
+			result.Error,
+			result.Chosen,
+		)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("person_id", personId.String()).
+				Str("field", result.Field).
+				Str("provider", result.Provider).
+				Msg("Failed to save enrichment result")
+			return err
+		}
+	}
+	return nil
+}
+
 func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int) {
 	log.Debug().
 		Str("person_id", person.Id.String()).
@@ -286,7 +600,7 @@ func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int)
 		argCnt++
 		log.Debug().
 			Str("person_id", person.Id.String()).
-			Str("name", *person.Name).
+			Func(redact.SensitiveStr(redact.FieldName, *person.Name)).
 			Msg("Adding name to update fields")
 	}
 
@@ -296,7 +610,7 @@ func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int)
 		argCnt++
 		log.Debug().
 			Str("person_id", person.Id.String()).
-			Str("surname", *person.Surname).
+			Func(redact.SensitiveStr(redact.FieldSurname, *person.Surname)).
 			Msg("Adding surname to update fields")
 	}
 
@@ -306,7 +620,7 @@ func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int)
 		argCnt++
 		log.Debug().
 			Str("person_id", person.Id.String()).
-			Str("patronymic", *person.Patronymic).
+			Func(redact.SensitiveStr(redact.FieldPatronymic, *person.Patronymic)).
 			Msg("Adding patronymic to update fields")
 	}
 
@@ -314,9 +628,12 @@ func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int)
 		setValues = append(setValues, fmt.Sprintf("age = $%d", argCnt))
 		args = append(args, *person.Age)
 		argCnt++
+		setValues = append(setValues, fmt.Sprintf("age_source = $%d", argCnt), "age_updated_at = now()")
+		args = append(args, models.SourceManual)
+		argCnt++
 		log.Debug().
 			Str("person_id", person.Id.String()).
-			Uint32("age", *person.Age).
+			Func(redact.SensitiveUint32(redact.FieldAge, *person.Age)).
 			Msg("Adding age to update fields")
 	}
 
@@ -324,9 +641,12 @@ func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int)
 		setValues = append(setValues, fmt.Sprintf("gender = $%d", argCnt))
 		args = append(args, *person.Gender)
 		argCnt++
+		setValues = append(setValues, fmt.Sprintf("gender_source = $%d", argCnt), "gender_updated_at = now()")
+		args = append(args, models.SourceManual)
+		argCnt++
 		log.Debug().
 			Str("person_id", person.Id.String()).
-			Str("gender", *person.Gender).
+			Func(redact.SensitiveStr(redact.FieldGender, *person.Gender)).
 			Msg("Adding gender to update fields")
 	}
 
@@ -334,9 +654,12 @@ func setArgumentsForUpdate(person dtos.PersonDto) ([]string, []interface{}, int)
 		setValues = append(setValues, fmt.Sprintf("country = $%d", argCnt))
 		args = append(args, *person.Country)
 		argCnt++
+		setValues = append(setValues, fmt.Sprintf("country_source = $%d", argCnt), "country_updated_at = now()")
+		args = append(args, models.SourceManual)
+		argCnt++
 		log.Debug().
 			Str("person_id", person.Id.String()).
-			Str("country", *person.Country).
+			Func(redact.SensitiveStr(redact.FieldCountry, *person.Country)).
 			Msg("Adding country to update fields")
 	}
 
@@ -430,7 +753,7 @@ func setArgumentsForGet(getPersonDto dtos.GetPersonDto) ([]string, []interface{}
 		args = append(args, *getPersonDto.Gender)
 		argCnt++
 		log.Debug().
-			Str("gender", *getPersonDto.Gender).
+			Func(redact.Str(redact.FieldGender, *getPersonDto.Gender)).
 			Msg("Adding gender filter to query")
 	}
 
@@ -447,6 +770,10 @@ func setArgumentsForGet(getPersonDto dtos.GetPersonDto) ([]string, []interface{}
 			Msg("Adding countries filter to query")
 	}
 
+	setValues, args, argCnt = setSourceArgumentsForGet("age_source", getPersonDto.AgeSources, setValues, args, argCnt)
+	setValues, args, argCnt = setSourceArgumentsForGet("gender_source", getPersonDto.GenderSources, setValues, args, argCnt)
+	setValues, args, argCnt = setSourceArgumentsForGet("country_source", getPersonDto.CountrySources, setValues, args, argCnt)
+
 	log.Debug().
 		Int("filter_conditions", len(setValues)).
 		Int("args_count", len(args)).
@@ -454,3 +781,55 @@ func setArgumentsForGet(getPersonDto dtos.GetPersonDto) ([]string, []interface{}
 
 	return setValues, args, argCnt
 }
+
+// setSourceArgumentsForGet matches the source column against the requested values.
+// The bare "enriched" value matches every enrichment provider.
+func setSourceArgumentsForGet(column string, sources []string, setValues []string, args []interface{}, argCnt int) ([]string, []interface{}, int) {
+	if len(sources) == 0 {
+		return setValues, args, argCnt
+	}
+
+	conditions := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if source == models.SourceEnriched {
+			conditions = append(conditions, fmt.Sprintf("%s LIKE $%d", column, argCnt))
+			args = append(args, models.EnrichedSource("%"))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", column, argCnt))
+			args = append(args, source)
+		}
+		argCnt++
+	}
+	setValues = append(setValues, "("+strings.Join(conditions, " OR ")+")")
+
+	log.Debug().
+		Str("column", column).
+		Int("sources_count", len(sources)).
+		Msg("Adding source filter to query")
+
+	return setValues, args, argCnt
+}
+
+// appliedChanges drops the changes of fields whose stored source differs from the intended one,
+// they were set manually while the enrichment was running.
+func appliedChanges(ctx context.Context, intended, stored *models.Person, changes []models.EnrichmentChange) []models.EnrichmentChange {
+	sources := map[string][2]string{
+		models.FieldAge:     {intended.AgeSource.Source, stored.AgeSource.Source},
+		models.FieldGender:  {intended.GenderSource.Source, stored.GenderSource.Source},
+		models.FieldCountry: {intended.CountrySource.Source, stored.CountrySource.Source},
+	}
+
+	applied := make([]models.EnrichmentChange, 0, len(changes))
+	for _, change := range changes {
+		if source := sources[change.Field]; source[0] != source[1] {
+			log.Ctx(ctx).Warn().
+				Str("person_id", stored.Id.String()).
+				Str("field", change.Field).
+				Str("source", source[1]).
+				Msg("Field was changed during enrichment, keeping the stored value")
+			continue
+		}
+		applied = append(applied, change)
+	}
+	return applied
+}
@@ -80,9 +80,13 @@ func setupPostgresContainer(t *testing.T) (*pgxpool.Pool, func()) {
 }
 
 func setupSchema(ctx context.Context, pool *pgxpool.Pool) error {
-	schema := createTestSchema
+	migrator, err := NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
 
-	_, err := pool.Exec(ctx, schema)
+	_, err = migrator.Up(ctx)
 	return err
 }
 
@@ -102,6 +106,11 @@ func createTestData(ctx context.Context, pool *pgxpool.Pool) ([]pgtype.UUID, err
 			gender = models.Female
 		}
 		country := "RU"
+		genderSource := models.EnrichedSource("genderize")
+		if i == 0 {
+			genderSource = models.SourceManual
+		}
+		now := time.Now()
 
 		_, err := pool.Exec(ctx, queryCreatePerson,
 			id,
@@ -111,6 +120,12 @@ func createTestData(ctx context.Context, pool *pgxpool.Pool) ([]pgtype.UUID, err
 			age,
 			gender,
 			country,
+			models.EnrichedSource("agify"),
+			now,
+			genderSource,
+			now,
+			models.EnrichedSource("nationalize"),
+			now,
 		)
 
 		if err != nil {
@@ -179,15 +194,28 @@ func TestCreatePerson(t *testing.T) {
 		Age:        10,
 		Gender:     models.Male,
 		Country:    "RU",
+
+		AgeSource:     models.AttributeSource{Source: models.EnrichedSource("agify"), UpdatedAt: time.Now()},
+		GenderSource:  models.AttributeSource{Source: models.SourceImport, UpdatedAt: time.Now()},
+		CountrySource: models.AttributeSource{Source: models.EnrichedSource("nationalize"), UpdatedAt: time.Now()},
 	}
 
-	err := driver.CreatePerson(ctx, person)
+	err := driver.CreatePerson(ctx, person, nil)
 	require.NoError(t, err)
 
 	expPerson, err := driver.GetPersonById(ctx, person.Id)
 
 	require.NoError(t, err)
-	assert.Equal(t, person, expPerson)
+	assert.Equal(t, person.Name, expPerson.Name)
+	assert.Equal(t, person.Surname, expPerson.Surname)
+	assert.Equal(t, person.Patronymic, expPerson.Patronymic)
+	assert.Equal(t, person.Age, expPerson.Age)
+	assert.Equal(t, person.Gender, expPerson.Gender)
+	assert.Equal(t, person.Country, expPerson.Country)
+	assert.Equal(t, person.AgeSource.Source, expPerson.AgeSource.Source)
+	assert.Equal(t, person.GenderSource.Source, expPerson.GenderSource.Source)
+	assert.Equal(t, person.CountrySource.Source, expPerson.CountrySource.Source)
+	assert.WithinDuration(t, person.AgeSource.UpdatedAt, expPerson.AgeSource.UpdatedAt, time.Millisecond)
 }
 
 func TestUpdatePerson(t *testing.T) {
@@ -215,6 +243,9 @@ func TestUpdatePerson(t *testing.T) {
 		assert.Equal(t, personIds[0], updatedPerson.Id)
 		assert.Equal(t, age, updatedPerson.Age)
 		assert.Equal(t, country, updatedPerson.Country)
+		assert.Equal(t, models.SourceManual, updatedPerson.AgeSource.Source)
+		assert.Equal(t, models.SourceManual, updatedPerson.CountrySource.Source)
+		assert.True(t, updatedPerson.GenderSource.IsEnriched())
 	})
 
 	t.Run("UpdatePerson without updating fields", func(t *testing.T) {
@@ -229,6 +260,95 @@ func TestUpdatePerson(t *testing.T) {
 	})
 }
 
+func TestApplyEnrichment(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewPersonDriver(pool)
+	ctx := context.Background()
+
+	personIds, err := createTestData(ctx, pool)
+	require.NoError(t, err)
+	require.NotEmpty(t, personIds)
+
+	person, err := driver.GetPersonById(ctx, personIds[0])
+	require.NoError(t, err)
+
+	person.Age = 44
+	changes := []models.EnrichmentChange{
+		{Field: models.FieldAge, OldValue: "10", NewValue: "44"},
+	}
+
+	results := []models.ProviderResult{
+		{Field: models.FieldAge, Provider: "agify", Value: "44", Confidence: 0.9, Chosen: true},
+		{Field: models.FieldAge, Provider: "local", Error: "name not found in local dataset"},
+	}
+
+	err = driver.ApplyEnrichment(ctx, person, changes, results)
+	require.NoError(t, err)
+
+	enrichedPerson, err := driver.GetPersonById(ctx, personIds[0])
+	require.NoError(t, err)
+	assert.Equal(t, uint32(44), enrichedPerson.Age)
+
+	var changesCount int
+	err = pool.QueryRow(ctx, "SELECT count(*) FROM person_enrichment_changes WHERE person_id = $1", personIds[0]).Scan(&changesCount)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changesCount)
+
+	storedResults, err := driver.GetEnrichmentResults(ctx, personIds[0])
+	require.NoError(t, err)
+	require.Len(t, storedResults, 2)
+	assert.True(t, storedResults[0].Chosen)
+	assert.Equal(t, "44", storedResults[0].Value)
+	assert.Equal(t, "name not found in local dataset", storedResults[1].Error)
+}
+
+func TestApplyEnrichmentKeepsManualChanges(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewPersonDriver(pool)
+	ctx := context.Background()
+
+	personIds, err := createTestData(ctx, pool)
+	require.NoError(t, err)
+
+	person, err := driver.GetPersonById(ctx, personIds[1])
+	require.NoError(t, err)
+
+	// The age is set manually after the person was read for the enrichment.
+	var manualAge uint32 = 33
+	_, err = driver.UpdatePerson(ctx, dtos.PersonDto{Id: personIds[1], Age: &manualAge})
+	require.NoError(t, err)
+
+	now := time.Now()
+	person.Age = 44
+	person.AgeSource = models.AttributeSource{Source: models.EnrichedSource("agify"), UpdatedAt: now}
+	person.Country = "KZ"
+	person.CountrySource = models.AttributeSource{Source: models.EnrichedSource("nationalize"), UpdatedAt: now}
+	changes := []models.EnrichmentChange{
+		{Field: models.FieldAge, OldValue: "20", NewValue: "44"},
+		{Field: models.FieldCountry, OldValue: "RU", NewValue: "KZ"},
+	}
+
+	err = driver.ApplyEnrichment(ctx, person, changes, nil)
+	require.NoError(t, err)
+	assert.Equal(t, manualAge, person.Age)
+	assert.Equal(t, models.SourceManual, person.AgeSource.Source)
+
+	storedPerson, err := driver.GetPersonById(ctx, personIds[1])
+	require.NoError(t, err)
+	assert.Equal(t, manualAge, storedPerson.Age)
+	assert.Equal(t, models.SourceManual, storedPerson.AgeSource.Source)
+	assert.Equal(t, "KZ", storedPerson.Country)
+
+	var fields []string
+	err = pool.QueryRow(ctx, "SELECT array_agg(field) FROM person_enrichment_changes WHERE person_id = $1", personIds[1]).Scan(&fields)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.FieldCountry}, fields)
+}
+
 func TestDeletePerson(t *testing.T) {
 	pool, cleanup := setupPostgresContainer(t)
 	defer cleanup()
@@ -244,6 +364,26 @@ func TestDeletePerson(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCountPersons(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewPersonDriver(pool)
+	ctx := context.Background()
+
+	personIds, err := createTestData(ctx, pool)
+	require.NoError(t, err)
+
+	limit := uint32(1)
+	count, err := driver.CountPersons(ctx, dtos.GetPersonDto{Limit: &limit})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(personIds)), count)
+
+	count, err = driver.CountPersons(ctx, dtos.GetPersonDto{Names: []string{"name0", "name1"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
 func TestGetPersons(t *testing.T) {
 	pool, cleanup := setupPostgresContainer(t)
 	defer cleanup()
@@ -294,4 +434,47 @@ func TestGetPersons(t *testing.T) {
 		require.Less(t, age, persons[0].Age)
 		require.Equal(t, "RU", persons[0].Country)
 	})
+
+	t.Run("GetPersons with source filters", func(t *testing.T) {
+		getPersonDto := dtos.GetPersonDto{
+			GenderSources: []string{models.SourceManual},
+		}
+
+		persons, err := driver.GetPersons(ctx, getPersonDto)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(persons))
+		require.Equal(t, "name0", persons[0].Name)
+
+		getPersonDto = dtos.GetPersonDto{
+			GenderSources: []string{models.SourceEnriched},
+			AgeSources:    []string{models.EnrichedSource("agify")},
+		}
+
+		persons, err = driver.GetPersons(ctx, getPersonDto)
+		require.NoError(t, err)
+		require.Equal(t, len(personIds)-1, len(persons))
+	})
+}
+
+func TestGetNameStatistics(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewPersonDriver(pool)
+	ctx := context.Background()
+
+	personIds, err := createTestData(ctx, pool)
+	require.NoError(t, err)
+	require.NotEmpty(t, personIds)
+
+	statistics, err := driver.GetNameStatistics(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(personIds), len(statistics))
+
+	assert.Equal(t, "name0", statistics[0].Name)
+	assert.Equal(t, 1, statistics[0].AgeCount)
+	assert.Equal(t, float64(10), statistics[0].MeanAge)
+	assert.Equal(t, 0, statistics[0].MaleCount)
+	assert.Equal(t, 1, statistics[0].FemaleCount)
+	assert.Equal(t, map[string]int{"RU": 1}, statistics[0].Countries)
 }
@@ -8,9 +8,13 @@ import (
 )
 
 type PersonDriverInterface interface {
-	CreatePerson(ctx context.Context, person *models.Person) error
+	CreatePerson(ctx context.Context, person *models.Person, results []models.ProviderResult) error
 	UpdatePerson(ctx context.Context, personDto dtos.PersonDto) (*models.Person, error)
+	ApplyEnrichment(ctx context.Context, person *models.Person, changes []models.EnrichmentChange, results []models.ProviderResult) error
 	DeletePerson(ctx context.Context, personId pgtype.UUID) error
 	GetPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) ([]models.Person, error)
+	CountPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (int64, error)
 	GetPersonById(ctx context.Context, id pgtype.UUID) (*models.Person, error)
+	GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]models.ProviderResult, error)
+	GetNameStatistics(ctx context.Context) ([]models.NameStatistics, error)
 }
@@ -0,0 +1,108 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/config"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+// Backoff bounds of the startup ping, the wait doubles after every failed attempt.
+const (
+	pingInitialBackoff = 500 * time.Millisecond
+	pingMaxBackoff     = 5 * time.Second
+)
+
+// NewPool creates the connection pool and waits up to ConnectTimeout for the database to
+// answer, so the application never starts against an unreachable database.
+func NewPool(ctx context.Context, databaseConfig config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := newPoolConfig(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().
+		Int32("max_conns", poolConfig.MaxConns).
+		Int32("min_conns", poolConfig.MinConns).
+		Dur("max_conn_lifetime", poolConfig.MaxConnLifetime).
+		Dur("max_conn_idle_time", poolConfig.MaxConnIdleTime).
+		Dur("health_check_period", poolConfig.HealthCheckPeriod).
+		Dur("statement_timeout", databaseConfig.StatementTimeout).
+		Msg("Creating connection pool")
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrCreatePool.Message)
+		return nil, custom_errors.ErrCreatePool
+	}
+
+	if err = ping(ctx, pool, databaseConfig.ConnectTimeout); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func newPoolConfig(databaseConfig config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(databaseConfig.ConnectionString)
+	if err != nil {
+		// The parse error may echo the connection string, so the password is not logged.
+		log.Error().Msg(custom_errors.ErrParsePoolConfig.Message)
+		return nil, custom_errors.ErrParsePoolConfig
+	}
+
+	if databaseConfig.MaxConns > 0 {
+		poolConfig.MaxConns = databaseConfig.MaxConns
+	}
+	poolConfig.MinConns = databaseConfig.MinConns
+	poolConfig.MaxConnLifetime = databaseConfig.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = databaseConfig.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = databaseConfig.HealthCheckPeriod
+	if databaseConfig.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(databaseConfig.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+// ping retries with exponential backoff until the database answers or wait runs out.
+func ping(ctx context.Context, pool *pgxpool.Pool, wait time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	backoff := pingInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := pool.Ping(ctx)
+		if err == nil {
+			log.Info().
+				Int("attempt", attempt).
+				Msg("Connected to database successfully")
+			return nil
+		}
+
+		log.Warn().
+			Err(err).
+			Int("attempt", attempt).
+			Dur("retry_in", backoff).
+			Msg("Database is not reachable yet")
+
+		select {
+		case <-ctx.Done():
+			log.Error().
+				Err(err).
+				Int("attempts", attempt).
+				Dur("waited", wait).
+				Msg(custom_errors.ErrConnectDatabase.Message)
+			return custom_errors.ErrConnectDatabase
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, pingMaxBackoff)
+	}
+}
@@ -2,37 +2,145 @@ package drivers
 
 const (
 	queryCreatePerson = `
-	INSERT INTO persons (id, name, surname, patronymic, age, gender, country)
-	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	INSERT INTO persons (
+		id, name, surname, patronymic, age, gender, country,
+		age_source, age_updated_at, gender_source, gender_updated_at, country_source, country_updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 `
 	queryDeletePerson = `
 	DELETE FROM persons 
 	WHERE id = $1
 `
 	queryGetPersons = `
-	SELECT id, name, surname, patronymic, age, gender, country
+	SELECT id, name, surname, patronymic, age, gender, country,
+		age_source, age_updated_at, gender_source, gender_updated_at, country_source, country_updated_at
+	FROM persons
+`
+	queryCountPersons = `
+	SELECT count(*)
 	FROM persons
 `
 	queryGetPersonById = `
-	SELECT name, surname, patronymic, age, gender, country
+	SELECT name, surname, patronymic, age, gender, country,
+		age_source, age_updated_at, gender_source, gender_updated_at, country_source, country_updated_at
 	FROM persons
 	WHERE id = $1
 `
-	createTestSchema = `
-	CREATE TYPE gender_type AS ENUM (
-		'male',
-		'female'
-		);
-	
-	CREATE TABLE IF NOT EXISTS persons
-	(
-		id UUID PRIMARY KEY,
-		name TEXT NOT NULL,
-		surname TEXT NOT NULL,
-		patronymic TEXT NOT NULL,
-		age INTEGER NOT NULL,
-		gender gender_type NOT NULL,
-		country TEXT
-	);
+	// A field is only written while its stored value is still enriched, so an update that
+	// lands while the providers are being called keeps its manual value.
+	queryApplyEnrichment = `
+	UPDATE persons
+	SET age = CASE WHEN age_source LIKE 'enriched:%' THEN $2 ELSE age END,
+		age_source = CASE WHEN age_source LIKE 'enriched:%' THEN $3 ELSE age_source END,
+		age_updated_at = CASE WHEN age_source LIKE 'enriched:%' THEN $4 ELSE age_updated_at END,
+		gender = CASE WHEN gender_source LIKE 'enriched:%' THEN $5 ELSE gender END,
+		gender_source = CASE WHEN gender_source LIKE 'enriched:%' THEN $6 ELSE gender_source END,
+		gender_updated_at = CASE WHEN gender_source LIKE 'enriched:%' THEN $7 ELSE gender_updated_at END,
+		country = CASE WHEN country_source LIKE 'enriched:%' THEN $8 ELSE country END,
+		country_source = CASE WHEN country_source LIKE 'enriched:%' THEN $9 ELSE country_source END,
+		country_updated_at = CASE WHEN country_source LIKE 'enriched:%' THEN $10 ELSE country_updated_at END
+	WHERE id = $1
+	RETURNING age, gender, country,
+		age_source, age_updated_at, gender_source, gender_updated_at, country_source, country_updated_at
+`
+	queryCreateEnrichmentChange = `
+	INSERT INTO person_enrichment_changes (person_id, field, old_value, new_value)
+	VALUES ($1, $2, $3, $4)
+`
+	queryCreateEnrichmentResult = `
+	INSERT INTO person_enrichment_results (person_id, field, provider, value, confidence, error, chosen)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7)
+`
+	queryGetEnrichmentResults = `
+	SELECT field, provider, COALESCE(value, ''), confidence, COALESCE(error, ''), chosen, created_at
+	FROM person_enrichment_results
+	WHERE person_id = $1
+	ORDER BY id
+`
+	queryGetNameStatistics = `
+	SELECT lower(name), count(*), avg(age)::float8,
+		count(*) FILTER (WHERE gender = 'male'),
+		count(*) FILTER (WHERE gender = 'female')
+	FROM persons
+	GROUP BY lower(name)
+	ORDER BY lower(name)
+`
+	queryGetNameCountries = `
+	SELECT lower(name), country, count(*)
+	FROM persons
+	WHERE country IS NOT NULL AND country <> ''
+	GROUP BY lower(name), country
+`
+	queryReserveIdempotencyKey = `
+	INSERT INTO idempotency_keys (principal, key, request_hash, expires_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (principal, key) DO UPDATE
+	SET request_hash = EXCLUDED.request_hash,
+		status_code = NULL,
+		content_type = NULL,
+		response_body = NULL,
+		created_at = now(),
+		expires_at = EXCLUDED.expires_at
+	WHERE idempotency_keys.expires_at <= now()
+`
+	queryGetIdempotencyKey = `
+	SELECT request_hash, COALESCE(status_code, 0), COALESCE(content_type, ''), COALESCE(response_body, ''::bytea), created_at, expires_at
+	FROM idempotency_keys
+	WHERE principal = $1 AND key = $2 AND expires_at > now()
+`
+	queryCompleteIdempotencyKey = `
+	UPDATE idempotency_keys
+	SET status_code = $3, content_type = $4, response_body = $5
+	WHERE principal = $1 AND key = $2
+`
+	queryDeleteIdempotencyKey = `
+	DELETE FROM idempotency_keys
+	WHERE principal = $1 AND key = $2
+`
+	queryDeleteExpiredIdempotencyKeys = `
+	DELETE FROM idempotency_keys
+	WHERE expires_at <= now()
+`
+	queryCreateApiKey = `
+	INSERT INTO api_keys (name, prefix, key_hash, roles)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (name) DO NOTHING
+	RETURNING id, created_at
+`
+	queryGetApiKeyByHash = `
+	SELECT id, name, prefix, key_hash, roles, created_at, revoked_at
+	FROM api_keys
+	WHERE key_hash = $1
+`
+	queryListApiKeys = `
+	SELECT id, name, prefix, key_hash, roles, created_at, revoked_at
+	FROM api_keys
+	ORDER BY id
+`
+	queryRevokeApiKey = `
+	UPDATE api_keys
+	SET revoked_at = now()
+	WHERE name = $1 AND revoked_at IS NULL
+`
+	// queryTakeRateLimitToken refills the bucket for the time since the last request and takes
+	// a token when a whole one is available. allowed keeps the outcome for RETURNING, which only
+	// sees the new row. $2 is the burst and $3 the refill rate per second.
+	queryTakeRateLimitToken = `
+	INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+	VALUES ($1, $2::float8 - 1, true, now())
+	ON CONFLICT (key) DO UPDATE
+	SET tokens = CASE
+			WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $3::float8) >= 1
+			THEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $3::float8) - 1
+			ELSE LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $3::float8)
+		END,
+		allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $3::float8) >= 1,
+		updated_at = now()
+	RETURNING tokens, allowed
+`
+	queryDeleteIdleRateLimitBuckets = `
+	DELETE FROM rate_limit_buckets
+	WHERE updated_at < now() - $1::interval
 `
 )
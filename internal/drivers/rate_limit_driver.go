@@ -0,0 +1,55 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// RateLimitDriver keeps the token buckets in PostgreSQL, so all replicas share the limits.
+// The buckets are refilled with the database clock, which replicas agree on.
+type RateLimitDriver struct {
+	adapter Adapter
+}
+
+func NewRateLimitDriver(adapter Adapter) *RateLimitDriver {
+	log.Debug().Msg("Initializing RateLimitDriver")
+	return &RateLimitDriver{adapter: adapter}
+}
+
+func (d *RateLimitDriver) TakeToken(ctx context.Context, key string, limit models.RateLimit) (float64, bool, error) {
+	log.Ctx(ctx).Trace().
+		Str("rate_limit_key", key).
+		Msg("Taking rate limit token from database")
+
+	var tokens float64
+	var allowed bool
+	err := d.adapter.QueryRow(ctx, queryTakeRateLimitToken, key, float64(limit.Burst), limit.Rate).Scan(&tokens, &allowed)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("rate_limit_key", key).
+			Msg(custom_errors.ErrTakeRateLimitToken.Message)
+		return 0, false, custom_errors.ErrTakeRateLimitToken
+	}
+
+	return tokens, allowed, nil
+}
+
+func (d *RateLimitDriver) DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	log.Ctx(ctx).Debug().
+		Dur("idle", idle).
+		Msg("Deleting idle rate limit buckets")
+
+	tag, err := d.adapter.Exec(ctx, queryDeleteIdleRateLimitBuckets, idle)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Msg(custom_errors.ErrDeleteRateLimitBuckets.Message)
+		return 0, custom_errors.ErrDeleteRateLimitBuckets
+	}
+
+	return tag.RowsAffected(), nil
+}
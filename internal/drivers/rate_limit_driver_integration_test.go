@@ -0,0 +1,56 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRateLimitDriver(t *testing.T) {
+	pool, cleanup := setupPostgresContainer(t)
+	defer cleanup()
+
+	driver := NewRateLimitDriver(pool)
+	ctx := context.Background()
+
+	// The refill rate is low enough for the bucket not to gain a whole token during the test.
+	limit := models.RateLimit{Rate: 0.001, Burst: 2}
+
+	t.Run("Burst is allowed", func(t *testing.T) {
+		tokens, allowed, err := driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 1.0, tokens)
+
+		tokens, allowed, err = driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Less(t, tokens, 1.0)
+	})
+
+	t.Run("Empty bucket rejects", func(t *testing.T) {
+		tokens, allowed, err := driver.TakeToken(ctx, "create:ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Less(t, tokens, 1.0)
+	})
+
+	t.Run("Other keys have own buckets", func(t *testing.T) {
+		_, allowed, err := driver.TakeToken(ctx, "create:ip:10.0.0.2", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("DeleteIdleBuckets", func(t *testing.T) {
+		deleted, err := driver.DeleteIdleBuckets(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+
+		deleted, err = driver.DeleteIdleBuckets(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+	})
+}
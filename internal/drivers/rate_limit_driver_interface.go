@@ -0,0 +1,15 @@
+package drivers
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"time"
+)
+
+type RateLimitDriverInterface interface {
+	// TakeToken counts a request against the bucket of key and returns the tokens left in it
+	// and whether the request is allowed.
+	TakeToken(ctx context.Context, key string, limit models.RateLimit) (float64, bool, error)
+	// DeleteIdleBuckets forgets the buckets not used for longer than idle.
+	DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error)
+}
@@ -1,5 +1,6 @@
 package dtos
 
 type AgeDto struct {
-	Age uint32 `json:"age"`
+	Count int     `json:"count"`
+	Age   *uint32 `json:"age"`
 }
@@ -1,6 +1,7 @@
 package dtos
 
 type CountryDto struct {
+	Count     int            `json:"count"`
 	Countries []CountryIdDto `json:"country"`
 }
 
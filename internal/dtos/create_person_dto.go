@@ -2,7 +2,12 @@ package dtos
 
 // CreatePersonDto @Description Данные для создания новой записи о человеке
 type CreatePersonDto struct {
-	Name       string  `json:"name"`
-	Surname    string  `json:"surname"`
-	Patronymic *string `json:"patronymic,omitempty"`
+	Name       string  `json:"name" form:"name"`
+	Surname    string  `json:"surname" form:"surname"`
+	Patronymic *string `json:"patronymic,omitempty" form:"patronymic"`
+	Age        *uint32 `json:"age,omitempty" form:"-"`
+	Gender     *string `json:"gender,omitempty" form:"-"`
+	Country    *string `json:"country,omitempty" form:"-"`
+
+	CountryHint *string `json:"country_hint,omitempty" form:"country_hint"`
 }
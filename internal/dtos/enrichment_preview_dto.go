@@ -0,0 +1,33 @@
+package dtos
+
+// EnrichmentPreviewDto @Description Предполагаемые возраст, пол и национальность без сохранения в БД
+type EnrichmentPreviewDto struct {
+	Name    string             `json:"name"`
+	Age     AgeEstimateDto     `json:"age"`
+	Gender  GenderEstimateDto  `json:"gender"`
+	Country CountryEstimateDto `json:"country"`
+}
+
+// AgeEstimateDto @Description Предполагаемый возраст
+type AgeEstimateDto struct {
+	Value    uint32 `json:"value"`
+	Count    int    `json:"count"`
+	Provider string `json:"provider"`
+}
+
+// GenderEstimateDto @Description Предполагаемый пол и его вероятность
+type GenderEstimateDto struct {
+	Value       string  `json:"value"`
+	Probability float64 `json:"probability"`
+	Count       int     `json:"count"`
+	Provider    string  `json:"provider"`
+}
+
+// CountryEstimateDto @Description Наиболее вероятная национальность и остальные варианты
+type CountryEstimateDto struct {
+	Value       string         `json:"value"`
+	Probability float64        `json:"probability"`
+	Count       int            `json:"count"`
+	Provider    string         `json:"provider"`
+	Candidates  []CountryIdDto `json:"candidates"`
+}
@@ -0,0 +1,14 @@
+package dtos
+
+import "time"
+
+// EnrichmentProviderResultDto @Description Ответ одного источника при обогащении атрибута
+type EnrichmentProviderResultDto struct {
+	Field      string    `json:"field"`
+	Provider   string    `json:"provider"`
+	Value      string    `json:"value,omitempty"`
+	Confidence float64   `json:"confidence"`
+	Error      string    `json:"error,omitempty"`
+	Chosen     bool      `json:"chosen"`
+	CreatedAt  time.Time `json:"created_at"`
+}
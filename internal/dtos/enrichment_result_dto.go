@@ -0,0 +1,32 @@
+package dtos
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// EnrichmentResultDto @Description Результат повторного обогащения данных о человеке
+type EnrichmentResultDto struct {
+	Id            pgtype.UUID           `json:"id"`
+	Changes       []EnrichmentChangeDto `json:"changes"`
+	SkippedFields []string              `json:"skipped_fields"`
+	// Error описывает, почему человека не удалось обработать при массовом обогащении
+	Error *string `json:"error,omitempty"`
+	// ErrorCode этой ошибки, те же стабильные коды, что и в ответах об ошибках
+	ErrorCode *string `json:"error_code,omitempty" example:"enrichment_failed"`
+	// Err is the failure of the person, the handler turns it into Error and ErrorCode so that
+	// internal details stay in the logs.
+	Err error `json:"-" swaggerignore:"true"`
+}
+
+// EnrichmentChangeDto @Description Изменение одного атрибута при повторном обогащении
+type EnrichmentChangeDto struct {
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// EnrichmentBatchDto @Description Результат массового повторного обогащения
+type EnrichmentBatchDto struct {
+	Results []EnrichmentResultDto `json:"results"`
+	// SkippedCount людей, подходящих под фильтры после offset, но не обработанных из-за limit или
+	// ограничения размера пакета. Для продолжения повторите запрос с offset, увеличенным на размер results
+	SkippedCount int64 `json:"skipped_count" example:"0"`
+}
@@ -1,5 +1,7 @@
 package dtos
 
 type GenderDto struct {
-	Gender string `json:"gender"`
+	Count       int     `json:"count"`
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
 }
@@ -12,6 +12,11 @@ type GetPersonDto struct {
 	HighAge     *uint32       `json:"high_age"`
 	Gender      *string       `json:"gender"`
 	Countries   []string      `json:"countries"`
-	Limit       *uint32       `json:"limit"`
-	Offset      *uint32       `json:"offset"`
+
+	AgeSources     []string `json:"age_sources"`
+	GenderSources  []string `json:"gender_sources"`
+	CountrySources []string `json:"country_sources"`
+
+	Limit  *uint32 `json:"limit"`
+	Offset *uint32 `json:"offset"`
 }
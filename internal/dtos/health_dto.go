@@ -0,0 +1,30 @@
+package dtos
+
+import "time"
+
+// HealthDto @Description Ответ проверки живости процесса
+type HealthDto struct {
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ReadinessDto @Description Готовность принимать запросы с результатами проверок зависимостей
+type ReadinessDto struct {
+	Status       string               `json:"status"`
+	ShuttingDown bool                 `json:"shutting_down"`
+	Timestamp    int64                `json:"timestamp"`
+	Checks       []DependencyCheckDto `json:"checks"`
+}
+
+// DependencyCheckDto @Description Результат проверки одной зависимости
+type DependencyCheckDto struct {
+	Name string `json:"name"`
+	// Status: up, down или degraded
+	Status string `json:"status"`
+	// Critical зависимости в статусе down переводят сервис в not_ready
+	Critical  bool       `json:"critical"`
+	LatencyMs int64      `json:"latency_ms"`
+	Error     string     `json:"error,omitempty"`
+	Circuit   string     `json:"circuit,omitempty"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+}
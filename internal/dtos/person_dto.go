@@ -1,6 +1,9 @@
 package dtos
 
-import "github.com/jackc/pgx/v5/pgtype"
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
 
 // PersonDto @Description Полная информация о человеке
 type PersonDto struct {
@@ -11,4 +14,14 @@ type PersonDto struct {
 	Age        *uint32     `json:"age,omitempty"`
 	Gender     *string     `json:"gender,omitempty"`
 	Country    *string     `json:"country,omitempty"`
+
+	AgeSource     *AttributeSourceDto `json:"age_source,omitempty" readonly:"true"`
+	GenderSource  *AttributeSourceDto `json:"gender_source,omitempty" readonly:"true"`
+	CountrySource *AttributeSourceDto `json:"country_source,omitempty" readonly:"true"`
+}
+
+// AttributeSourceDto @Description Источник значения атрибута: enriched:<provider>, manual или import
+type AttributeSourceDto struct {
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
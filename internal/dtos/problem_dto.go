@@ -0,0 +1,27 @@
+package dtos
+
+// ProblemDto @Description Описание ошибки в формате RFC 7807 (application/problem+json)
+type ProblemDto struct {
+	Type   string `json:"type" example:"urn:effective-mobile:problem:person_not_found"`
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail" example:"person not found"`
+	// Instance путь запроса, вызвавшего ошибку
+	Instance string `json:"instance" example:"/persons/0195b4a2-5f1e-7c3a-9d4e-1a2b3c4d5e6f"`
+	// Code стабильный машиночитаемый код ошибки
+	Code      string `json:"code" example:"person_not_found"`
+	RequestId string `json:"request_id" example:"5f0c2d9e-8a41-4c7b-9a53-3e1f7d2b6c10"`
+	// Errors ошибки отдельных полей запроса
+	Errors []FieldErrorDto `json:"errors,omitempty"`
+	// RequiredRole роль, необходимая для операции, только для code=forbidden
+	RequiredRole string `json:"required_role,omitempty" example:"editor"`
+	// Roles роли вызывающего, только для code=forbidden
+	Roles []string `json:"roles,omitempty" example:"reader"`
+}
+
+// FieldErrorDto @Description Ошибка значения одного поля запроса
+type FieldErrorDto struct {
+	Field   string `json:"field" example:"age"`
+	Code    string `json:"code" example:"out_of_range"`
+	Message string `json:"message" example:"age must be between 0 and 150"`
+}
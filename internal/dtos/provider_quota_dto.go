@@ -0,0 +1,14 @@
+package dtos
+
+import "time"
+
+// ProviderQuotaDto @Description Остаток квоты внешнего сервиса обогащения
+type ProviderQuotaDto struct {
+	Provider         string     `json:"provider"`
+	ApiKeyConfigured bool       `json:"api_key_configured"`
+	Limit            *int       `json:"limit,omitempty"`
+	Remaining        *int       `json:"remaining,omitempty"`
+	ResetAt          *time.Time `json:"reset_at,omitempty"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+	Exhausted        bool       `json:"exhausted"`
+}
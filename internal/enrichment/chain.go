@@ -0,0 +1,78 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+type chainProvider[T comparable] struct {
+	providers     []Provider[T]
+	minConfidence float64
+}
+
+// NewFallbackProvider asks the providers in order and returns the first successful estimate.
+func NewFallbackProvider[T comparable](providers ...Provider[T]) Provider[T] {
+	return NewChainProvider(0, providers...)
+}
+
+// NewChainProvider asks the providers in order and returns the first estimate whose
+// confidence reaches minConfidence.
+func NewChainProvider[T comparable](minConfidence float64, providers ...Provider[T]) Provider[T] {
+	if len(providers) == 1 && minConfidence <= 0 {
+		return providers[0]
+	}
+	return &chainProvider[T]{providers: providers, minConfidence: minConfidence}
+}
+
+func (p *chainProvider[T]) Name() string {
+	return joinProviderNames(p.providers)
+}
+
+func (p *chainProvider[T]) Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[T], error) {
+	considered := make([]models.ProviderResult, 0, len(p.providers))
+	var lastErr error = custom_errors.ErrNoConfidentEstimate
+
+	for _, provider := range p.providers {
+		estimate, err := provider.Estimate(ctx, request)
+		if err != nil {
+			log.Ctx(ctx).Warn().
+				Err(err).
+				Str("provider", provider.Name()).
+				Msg("Provider failed, trying next one")
+			considered = append(considered, failedResult(provider.Name(), err))
+			lastErr = err
+
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if estimate.Probability < p.minConfidence {
+			log.Ctx(ctx).Debug().
+				Str("provider", provider.Name()).
+				Float64("confidence", estimate.Probability).
+				Float64("min_confidence", p.minConfidence).
+				Msg("Provider confidence below threshold, trying next one")
+			considered = append(considered, estimate.result(false))
+			lastErr = custom_errors.ErrNoConfidentEstimate
+			continue
+		}
+
+		estimate.Considered = append(considered, estimate.result(true))
+		return estimate, nil
+	}
+
+	return nil, lastErr
+}
+
+func joinProviderNames[T comparable](providers []Provider[T]) string {
+	names := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, ",")
+}
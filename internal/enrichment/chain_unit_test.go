@@ -0,0 +1,128 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type stubProvider[T comparable] struct {
+	name     string
+	estimate *Estimate[T]
+	err      error
+}
+
+func (p *stubProvider[T]) Name() string {
+	return p.name
+}
+
+func (p *stubProvider[T]) Estimate(_ context.Context, _ models.EnrichmentRequest) (*Estimate[T], error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	estimate := *p.estimate
+	estimate.Provider = p.name
+	return &estimate, nil
+}
+
+func stubGender(name string, gender models.GenderType, probability float64) GenderProvider {
+	return &stubProvider[models.GenderType]{
+		name:     name,
+		estimate: &Estimate[models.GenderType]{Value: gender, Probability: probability},
+	}
+}
+
+func TestChainProvider(t *testing.T) {
+	ctx := context.Background()
+	request := models.EnrichmentRequest{Name: "Sasha"}
+	failing := &stubProvider[models.GenderType]{name: "genderize", err: custom_errors.ErrHttpGet}
+
+	t.Run("Chain skips failing and unconfident providers", func(t *testing.T) {
+		provider := NewChainProvider(0.8, failing, stubGender("local", models.Female, 0.6), stubGender("rules", models.Male, 0.9))
+
+		estimate, err := provider.Estimate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, models.Male, estimate.Value)
+		assert.Equal(t, "rules", estimate.Provider)
+
+		require.Len(t, estimate.Considered, 3)
+		assert.Equal(t, custom_errors.ErrHttpGet.Error(), estimate.Considered[0].Error)
+		assert.False(t, estimate.Considered[1].Chosen)
+		assert.True(t, estimate.Considered[2].Chosen)
+	})
+
+	t.Run("Chain without confident providers", func(t *testing.T) {
+		provider := NewChainProvider(0.95, stubGender("local", models.Female, 0.6), stubGender("rules", models.Male, 0.9))
+
+		estimate, err := provider.Estimate(ctx, request)
+		assert.Nil(t, estimate)
+		assert.Equal(t, custom_errors.ErrNoConfidentEstimate, err)
+	})
+}
+
+func TestVoteProvider(t *testing.T) {
+	ctx := context.Background()
+	request := models.EnrichmentRequest{Name: "Sasha"}
+
+	t.Run("Majority vote uses weights", func(t *testing.T) {
+		weights := map[string]float64{"rules": 2}
+		provider := NewMajorityVoteProvider(weights, 0,
+			stubGender("genderize", models.Female, 0.7),
+			stubGender("local", models.Female, 0.6),
+			stubGender("rules", models.Male, 0.9),
+		)
+
+		estimate, err := provider.Estimate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, models.Male, estimate.Value)
+		assert.Equal(t, "rules", estimate.Provider)
+		assert.InDelta(t, 1.8/3.1, estimate.Probability, 1e-9)
+		require.Len(t, estimate.Considered, 3)
+		assert.True(t, estimate.Considered[2].Chosen)
+	})
+
+	t.Run("Mean age vote", func(t *testing.T) {
+		provider := NewMeanAgeVoteProvider(nil, 0,
+			&stubProvider[uint32]{name: "agify", estimate: &Estimate[uint32]{Value: 40, Probability: 0.5}},
+			&stubProvider[uint32]{name: "local", estimate: &Estimate[uint32]{Value: 50, Probability: 0.5}},
+			&stubProvider[uint32]{name: "broken", err: custom_errors.ErrGotNoAge},
+		)
+
+		estimate, err := provider.Estimate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(45), estimate.Value)
+		assert.Len(t, estimate.Considered, 3)
+	})
+
+	t.Run("Vote with all weights 0", func(t *testing.T) {
+		weights := map[string]float64{"agify": 0, "local": 0, "genderize": 0}
+
+		ageProvider := NewMeanAgeVoteProvider(weights, 0,
+			&stubProvider[uint32]{name: "agify", estimate: &Estimate[uint32]{Value: 40, Probability: 0.5}},
+			&stubProvider[uint32]{name: "local", estimate: &Estimate[uint32]{Value: 50}},
+		)
+		age, err := ageProvider.Estimate(ctx, request)
+		assert.ErrorIs(t, err, custom_errors.ErrNoConfidentEstimate)
+		assert.Nil(t, age)
+
+		genderProvider := NewMajorityVoteProvider(weights, 0,
+			stubGender("genderize", models.Female, 0.7),
+			stubGender("local", models.Male, 0),
+		)
+		gender, err := genderProvider.Estimate(ctx, request)
+		assert.ErrorIs(t, err, custom_errors.ErrNoConfidentEstimate)
+		assert.Nil(t, gender)
+	})
+}
+
+func TestParseWeights(t *testing.T) {
+	weights, err := ParseWeights("genderize=1, rules=2.5")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"genderize": 1, "rules": 2.5}, weights)
+
+	_, err = ParseWeights("genderize")
+	assert.Equal(t, custom_errors.ErrParseWeights, err)
+}
@@ -0,0 +1,72 @@
+package enrichment
+
+import (
+	"context"
+	"sync"
+)
+
+type inflightCall[T any] struct {
+	done    chan struct{}
+	result  T
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalescer lets concurrent callers with the same key share one in-flight call. The call runs
+// detached from any single caller's context and is cancelled only when every caller waiting
+// for it has given up.
+type coalescer[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall[T]
+}
+
+func newCoalescer[T any]() *coalescer[T] {
+	return &coalescer[T]{calls: make(map[string]*inflightCall[T])}
+}
+
+// do returns the result of fn for key and whether it was shared with another caller.
+func (c *coalescer[T]) do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, bool, error) {
+	c.mu.Lock()
+	call, shared := c.calls[key]
+	if shared {
+		call.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &inflightCall[T]{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		c.calls[key] = call
+
+		go func() {
+			defer cancel()
+			call.result, call.err = fn(callCtx)
+
+			c.mu.Lock()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+			c.mu.Unlock()
+
+			close(call.done)
+		}()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, shared, call.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			// Later callers must not join a call that is being cancelled.
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+
+		var zero T
+		return zero, shared, ctx.Err()
+	}
+}
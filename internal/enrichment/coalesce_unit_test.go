@@ -0,0 +1,126 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescedLookups(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"count":300,"name":"Anna","age":44}`))
+	}))
+	defer server.Close()
+
+	provider := NewAgifyProvider(server.URL+"/?name=", "", nil)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"Anna", "anna", " ANNA", "Anna"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			estimate, err := provider.Estimate(context.Background(), models.EnrichmentRequest{Name: name})
+			assert.NoError(t, err)
+			assert.Equal(t, uint32(44), estimate.Value)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+
+	t.Run("Different country hints are not coalesced", func(t *testing.T) {
+		requests.Store(0)
+
+		var wg sync.WaitGroup
+		for _, countryId := range []string{"RU", "UA"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := provider.Estimate(context.Background(), models.EnrichmentRequest{Name: "Anna", CountryId: countryId})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), requests.Load())
+	})
+}
+
+func TestCoalescerCancellation(t *testing.T) {
+	t.Run("One caller giving up does not cancel the others", func(t *testing.T) {
+		group := newCoalescer[int]()
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		fn := func(ctx context.Context) (int, error) {
+			close(started)
+			select {
+			case <-release:
+				return 42, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+
+		cancelledCtx, cancel := context.WithCancel(context.Background())
+		cancelledErr := make(chan error, 1)
+		go func() {
+			_, _, err := group.do(cancelledCtx, "anna", fn)
+			cancelledErr <- err
+		}()
+		<-started
+
+		result := make(chan int, 1)
+		go func() {
+			value, shared, err := group.do(context.Background(), "anna", fn)
+			assert.NoError(t, err)
+			assert.True(t, shared)
+			result <- value
+		}()
+
+		// Give the second caller time to join before the first one leaves.
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-cancelledErr, context.Canceled)
+
+		close(release)
+		assert.Equal(t, 42, <-result)
+	})
+
+	t.Run("Call is cancelled when every caller gives up", func(t *testing.T) {
+		group := newCoalescer[int]()
+		callCancelled := make(chan struct{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _, err := group.do(ctx, "anna", func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				close(callCancelled)
+				return 0, ctx.Err()
+			})
+			assert.ErrorIs(t, err, context.Canceled)
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		<-done
+
+		select {
+		case <-callCancelled:
+		case <-time.After(time.Second):
+			require.Fail(t, "in-flight call was not cancelled")
+		}
+	})
+}
@@ -0,0 +1,545 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/redact"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var countryIdRegexp = regexp.MustCompile(`^[A-Z]{2}$`)
+
+const (
+	LocalModeOff      = "off"
+	LocalModePrimary  = "primary"
+	LocalModeFallback = "fallback"
+
+	GenderRulesOff     = "off"
+	GenderRulesBefore  = "before"
+	GenderRulesInstead = "instead"
+
+	StrategyFallback = "fallback"
+	StrategyVote     = "vote"
+)
+
+type Enricher struct {
+	ageProvider     AgeProvider
+	genderProvider  GenderProvider
+	countryProvider CountryProvider
+
+	// defaultCountry is the country_id used when the request has no country hint.
+	defaultCountry string
+	// localized resolves the country first and passes it to the age and gender providers.
+	localized bool
+	// timeout bounds the whole enrichment, including the localization phase.
+	timeout time.Duration
+}
+
+// NewEnricher creates an Enricher backed by the HTTP providers at the given base URLs.
+func NewEnricher(ageUrl string, genderUrl string, countryUrl string) *Enricher {
+	return NewEnricherWithProviders(
+		NewAgifyProvider(ageUrl, "", nil),
+		NewGenderizeProvider(genderUrl, "", nil),
+		NewNationalizeProvider(countryUrl, "", nil),
+	)
+}
+
+func NewEnricherWithProviders(ageProvider AgeProvider, genderProvider GenderProvider, countryProvider CountryProvider) *Enricher {
+	log.Debug().
+		Str("age_provider", ageProvider.Name()).
+		Str("gender_provider", genderProvider.Name()).
+		Str("country_provider", countryProvider.Name()).
+		Msg("Initializing Enricher")
+	return &Enricher{
+		ageProvider:     ageProvider,
+		genderProvider:  genderProvider,
+		countryProvider: countryProvider,
+	}
+}
+
+type EnricherOptions struct {
+	// LocalMode controls the local dataset: "off" uses only the HTTP providers, "primary" asks the
+	// dataset first and "fallback" asks it only when the HTTP provider fails.
+	LocalMode   string
+	DatasetPath string
+
+	// GenderRules controls the patronymic and surname rules: "off", "before" the other gender
+	// providers or "instead" of the HTTP gender API.
+	GenderRules string
+
+	// AgeProviders, GenderProviders and CountryProviders list provider names per attribute.
+	// When a list is empty it is derived from LocalMode and GenderRules.
+	AgeProviders     []string
+	GenderProviders  []string
+	CountryProviders []string
+
+	// Strategy is "fallback" to take the first provider above MinConfidence or "vote" to
+	// combine all providers weighted by Weights.
+	Strategy      string
+	MinConfidence float64
+	Weights       map[string]float64
+
+	// DefaultCountry is passed as country_id to agify and genderize when no hint is given.
+	DefaultCountry string
+	// Localized enables the two-phase mode: nationality first, then age and gender localized to it.
+	Localized bool
+
+	// Urls maps HTTP provider names to their base URLs, the name is appended to them.
+	Urls map[string]string
+	// ApiKeys maps HTTP provider names to their API keys.
+	ApiKeys map[string]string
+	// Quota records the rate limits of the HTTP providers. It may be nil.
+	Quota *QuotaTracker
+
+	// Timeout is the overall deadline of one enrichment. Zero means no deadline.
+	Timeout time.Duration
+}
+
+func NewConfiguredEnricher(options EnricherOptions) (*Enricher, error) {
+	ageNames, genderNames, countryNames, err := providerNames(options)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &providerRegistry{
+		datasetPath: options.DatasetPath,
+		urls:        options.Urls,
+		apiKeys:     options.ApiKeys,
+		quota:       options.Quota,
+	}
+
+	ageProviders, err := buildProviders(ageNames, registry.ageProvider)
+	if err != nil {
+		return nil, err
+	}
+	genderProviders, err := buildProviders(genderNames, registry.genderProvider)
+	if err != nil {
+		return nil, err
+	}
+	countryProviders, err := buildProviders(countryNames, registry.countryProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Strs("age_providers", ageNames).
+		Strs("gender_providers", genderNames).
+		Strs("country_providers", countryNames).
+		Str("strategy", options.Strategy).
+		Float64("min_confidence", options.MinConfidence).
+		Msg("Configured enrichment providers")
+
+	if options.DefaultCountry != "" && !countryIdRegexp.MatchString(options.DefaultCountry) {
+		log.Error().
+			Str("country", options.DefaultCountry).
+			Msg(custom_errors.ErrInvalidDefaultCountry.Message)
+		return nil, custom_errors.ErrInvalidDefaultCountry
+	}
+
+	var enricher *Enricher
+	switch options.Strategy {
+	case "", StrategyFallback:
+		enricher = NewEnricherWithProviders(
+			NewChainProvider(options.MinConfidence, ageProviders...),
+			NewChainProvider(options.MinConfidence, genderProviders...),
+			NewChainProvider(options.MinConfidence, countryProviders...),
+		)
+	case StrategyVote:
+		enricher = NewEnricherWithProviders(
+			NewMeanAgeVoteProvider(options.Weights, options.MinConfidence, ageProviders...),
+			NewMajorityVoteProvider(options.Weights, options.MinConfidence, genderProviders...),
+			NewMajorityVoteProvider(options.Weights, options.MinConfidence, countryProviders...),
+		)
+	default:
+		log.Error().
+			Str("strategy", options.Strategy).
+			Msg(custom_errors.ErrInvalidStrategy.Message)
+		return nil, custom_errors.ErrInvalidStrategy
+	}
+
+	enricher.defaultCountry = options.DefaultCountry
+	enricher.localized = options.Localized
+	enricher.timeout = options.Timeout
+
+	return enricher, nil
+}
+
+// providerNames returns the configured provider lists, filling the missing ones from the
+// LocalMode and GenderRules settings.
+func providerNames(options EnricherOptions) ([]string, []string, []string, error) {
+	ageNames := []string{ProviderAgify}
+	genderNames := []string{ProviderGenderize}
+	countryNames := []string{ProviderNationalize}
+
+	switch options.GenderRules {
+	case "", GenderRulesOff:
+	case GenderRulesBefore:
+		genderNames = []string{ProviderRules, ProviderGenderize}
+	case GenderRulesInstead:
+		genderNames = []string{ProviderRules}
+	default:
+		log.Error().
+			Str("mode", options.GenderRules).
+			Msg(custom_errors.ErrInvalidGenderRulesMode.Message)
+		return nil, nil, nil, custom_errors.ErrInvalidGenderRulesMode
+	}
+
+	switch options.LocalMode {
+	case "", LocalModeOff:
+	case LocalModePrimary:
+		ageNames = []string{ProviderLocal, ProviderAgify}
+		countryNames = []string{ProviderLocal, ProviderNationalize}
+		genderNames = insertBefore(genderNames, ProviderGenderize, ProviderLocal)
+	case LocalModeFallback:
+		ageNames = append(ageNames, ProviderLocal)
+		countryNames = append(countryNames, ProviderLocal)
+		genderNames = append(genderNames, ProviderLocal)
+	default:
+		log.Error().
+			Str("mode", options.LocalMode).
+			Msg(custom_errors.ErrInvalidLocalMode.Message)
+		return nil, nil, nil, custom_errors.ErrInvalidLocalMode
+	}
+
+	if len(options.AgeProviders) > 0 {
+		ageNames = options.AgeProviders
+	}
+	if len(options.GenderProviders) > 0 {
+		genderNames = options.GenderProviders
+	}
+	if len(options.CountryProviders) > 0 {
+		countryNames = options.CountryProviders
+	}
+
+	return ageNames, genderNames, countryNames, nil
+}
+
+// insertBefore puts name in front of target, or at the end when target is not in the list.
+func insertBefore(names []string, target string, name string) []string {
+	for i, n := range names {
+		if n == target {
+			return append(names[:i], append([]string{name}, names[i:]...)...)
+		}
+	}
+	return append(names, name)
+}
+
+func buildProviders[T comparable](names []string, build func(name string) (Provider[T], error)) ([]Provider[T], error) {
+	providers := make([]Provider[T], 0, len(names))
+	for _, name := range names {
+		provider, err := build(name)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// providerRegistry creates providers by name and loads the local dataset at most once.
+type providerRegistry struct {
+	datasetPath string
+	dataset     *LocalDataset
+	urls        map[string]string
+	apiKeys     map[string]string
+	quota       *QuotaTracker
+}
+
+func (r *providerRegistry) localDataset() (*LocalDataset, error) {
+	if r.dataset == nil {
+		dataset, err := LoadDataset(r.datasetPath)
+		if err != nil {
+			return nil, err
+		}
+		r.dataset = dataset
+	}
+	return r.dataset, nil
+}
+
+func (r *providerRegistry) ageProvider(name string) (AgeProvider, error) {
+	switch name {
+	case ProviderAgify:
+		return NewAgifyProvider(r.urls[ProviderAgify], r.apiKeys[ProviderAgify], r.quota), nil
+	case ProviderLocal:
+		dataset, err := r.localDataset()
+		if err != nil {
+			return nil, err
+		}
+		return dataset.AgeProvider(), nil
+	}
+	return nil, unknownProvider(models.FieldAge, name)
+}
+
+func (r *providerRegistry) genderProvider(name string) (GenderProvider, error) {
+	switch name {
+	case ProviderGenderize:
+		return NewGenderizeProvider(r.urls[ProviderGenderize], r.apiKeys[ProviderGenderize], r.quota), nil
+	case ProviderRules:
+		return NewRulesGenderProvider(), nil
+	case ProviderLocal:
+		dataset, err := r.localDataset()
+		if err != nil {
+			return nil, err
+		}
+		return dataset.GenderProvider(), nil
+	}
+	return nil, unknownProvider(models.FieldGender, name)
+}
+
+func (r *providerRegistry) countryProvider(name string) (CountryProvider, error) {
+	switch name {
+	case ProviderNationalize:
+		return NewNationalizeProvider(r.urls[ProviderNationalize], r.apiKeys[ProviderNationalize], r.quota), nil
+	case ProviderLocal:
+		dataset, err := r.localDataset()
+		if err != nil {
+			return nil, err
+		}
+		return dataset.CountryProvider(), nil
+	}
+	return nil, unknownProvider(models.FieldCountry, name)
+}
+
+func unknownProvider(field string, name string) error {
+	log.Error().
+		Str("field", field).
+		Str("provider", name).
+		Msg(custom_errors.ErrUnknownProvider.Message)
+	return custom_errors.ErrUnknownProvider
+}
+
+func (e *Enricher) Enrich(ctx context.Context, request models.EnrichmentRequest) (*models.Enrichment, error) {
+	name := request.Name
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	request, country, err := e.localize(ctx, request)
+	if err != nil {
+		return nil, &custom_errors.EnrichmentError{Failures: []custom_errors.ProviderFailure{
+			newProviderFailure(ctx, models.FieldCountry, e.countryProvider.Name(), err),
+		}}
+	}
+
+	var age *Estimate[uint32]
+	var gender *Estimate[models.GenderType]
+
+	// The first failing provider cancels the others through groupCtx.
+	group, groupCtx := errgroup.WithContext(ctx)
+	failures := &failureRecorder{}
+
+	log.Ctx(ctx).Debug().
+		Str("country_id", request.CountryId).
+		Strs("fields", request.Fields).
+		Msg("Starting goroutines to fetch person attributes")
+
+	if request.Wants(models.FieldAge) {
+		group.Go(func() error {
+			log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, name)).Msg("Fetching age")
+			estimate, err := e.ageProvider.Estimate(groupCtx, request)
+			if err != nil {
+				return failures.record(ctx, models.FieldAge, e.ageProvider.Name(), err)
+			}
+			log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, name)).Func(redact.Uint32(redact.FieldAge, estimate.Value)).Msg("Age fetched successfully")
+			age = estimate
+			return nil
+		})
+	}
+
+	if request.Wants(models.FieldGender) {
+		group.Go(func() error {
+			log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, name)).Msg("Fetching gender")
+			estimate, err := e.genderProvider.Estimate(groupCtx, request)
+			if err != nil {
+				return failures.record(ctx, models.FieldGender, e.genderProvider.Name(), err)
+			}
+			log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, name)).Func(redact.Str(redact.FieldGender, string(estimate.Value))).Msg("Gender fetched successfully")
+			gender = estimate
+			return nil
+		})
+	}
+
+	if request.Wants(models.FieldCountry) && country == nil {
+		group.Go(func() error {
+			log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, name)).Msg("Fetching country")
+			estimate, err := e.countryProvider.Estimate(groupCtx, request)
+			if err != nil {
+				return failures.record(ctx, models.FieldCountry, e.countryProvider.Name(), err)
+			}
+			log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, name)).Func(redact.Str(redact.FieldCountry, estimate.Value)).Msg("Country fetched successfully")
+			country = estimate
+			return nil
+		})
+	}
+
+	log.Ctx(ctx).Debug().Msg("Waiting for person attributes")
+	if err := group.Wait(); err != nil {
+		enrichmentErr := &custom_errors.EnrichmentError{Failures: failures.failures}
+		log.Ctx(ctx).Error().
+			Err(enrichmentErr).
+			Func(redact.Str(redact.FieldName, name)).
+			Msg("Failed to enrich person attributes")
+		return nil, enrichmentErr
+	}
+
+	enrichment := &models.Enrichment{}
+
+	if age != nil {
+		enrichment.Age = age.Value
+		enrichment.Results = appendResults(enrichment.Results, models.FieldAge, age.considered())
+		enrichment.AgeProvider = age.Provider
+		enrichment.AgeCount = age.Count
+	}
+
+	if gender != nil {
+		enrichment.Gender = gender.Value
+		enrichment.Results = appendResults(enrichment.Results, models.FieldGender, gender.considered())
+		enrichment.GenderProvider = gender.Provider
+		enrichment.GenderProbability = gender.Probability
+		enrichment.GenderCount = gender.Count
+	}
+
+	if request.Wants(models.FieldCountry) && country != nil {
+		enrichment.Country = country.Value
+		enrichment.Results = appendResults(enrichment.Results, models.FieldCountry, country.considered())
+		enrichment.CountryProvider = country.Provider
+		enrichment.CountryProbability = country.Probability
+		enrichment.CountryCount = country.Count
+		enrichment.CountryCandidates = country.Candidates
+	}
+
+	return enrichment, nil
+}
+
+// newProviderFailure describes a provider error, replacing it with the reason when the whole
+// enrichment ran out of time or was cancelled by the caller.
+func newProviderFailure(ctx context.Context, field string, provider string, err error) custom_errors.ProviderFailure {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = custom_errors.ErrEnrichmentTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		err = custom_errors.ErrEnrichmentCancel
+	}
+
+	log.Ctx(ctx).Warn().
+		Err(err).
+		Str("field", field).
+		Str("provider", provider).
+		Msg("Enrichment provider failed")
+
+	return custom_errors.ProviderFailure{Field: field, Provider: provider, Err: err}
+}
+
+type failureRecorder struct {
+	mu       sync.Mutex
+	failures []custom_errors.ProviderFailure
+}
+
+// record keeps the failure unless the provider was only cancelled because a sibling had
+// already failed. It returns the error for the errgroup.
+func (r *failureRecorder) record(ctx context.Context, field string, provider string, err error) error {
+	if ctx.Err() == nil && errors.Is(err, context.Canceled) {
+		log.Ctx(ctx).Debug().
+			Str("field", field).
+			Str("provider", provider).
+			Msg("Enrichment provider cancelled after a sibling failure")
+		return err
+	}
+
+	failure := newProviderFailure(ctx, field, provider, err)
+
+	r.mu.Lock()
+	r.failures = append(r.failures, failure)
+	r.mu.Unlock()
+
+	return failure.Err
+}
+
+// localize picks the country_id passed to the age and gender providers. A hint given in the
+// request wins; otherwise in localized mode the country is resolved first, and the configured
+// default country is used as the last resort. The country estimate is returned when it was
+// resolved here, so that it is not requested twice.
+func (e *Enricher) localize(ctx context.Context, request models.EnrichmentRequest) (models.EnrichmentRequest, *Estimate[string], error) {
+	if request.CountryId != "" {
+		return request, nil, nil
+	}
+
+	var country *Estimate[string]
+	if e.localized && (request.Wants(models.FieldAge) || request.Wants(models.FieldGender)) {
+		log.Ctx(ctx).Debug().Func(redact.Str(redact.FieldName, request.Name)).Msg("Resolving country before age and gender")
+
+		var err error
+		country, err = e.countryProvider.Estimate(ctx, request)
+		if err != nil {
+			if request.Wants(models.FieldCountry) {
+				log.Ctx(ctx).Error().
+					Err(err).
+					Func(redact.Str(redact.FieldName, request.Name)).
+					Msg("Failed to get country")
+				return request, nil, err
+			}
+
+			log.Ctx(ctx).Warn().
+				Err(err).
+				Func(redact.Str(redact.FieldName, request.Name)).
+				Msg("Failed to resolve country, age and gender will not be localized")
+		} else {
+			request.CountryId = country.Value
+		}
+	}
+
+	if request.CountryId == "" {
+		request.CountryId = e.defaultCountry
+	}
+
+	return request, country, nil
+}
+
+func appendResults(results []models.ProviderResult, field string, considered []models.ProviderResult) []models.ProviderResult {
+	for _, result := range considered {
+		result.Field = field
+		results = append(results, result)
+	}
+	return results
+}
+
+// ParseWeights reads provider weights written as "genderize=1,rules=2".
+func ParseWeights(value string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	if strings.TrimSpace(value) == "" {
+		return weights, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		provider, weight, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			log.Error().
+				Str("weights", value).
+				Msg(custom_errors.ErrParseWeights.Message)
+			return nil, custom_errors.ErrParseWeights
+		}
+
+		parsed, err := strconv.ParseFloat(weight, 64)
+		if err != nil || parsed < 0 {
+			log.Error().
+				Err(err).
+				Str("weights", value).
+				Msg(custom_errors.ErrParseWeights.Message)
+			return nil, custom_errors.ErrParseWeights
+		}
+		weights[provider] = parsed
+	}
+
+	return weights, nil
+}
@@ -0,0 +1,10 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+)
+
+type EnricherInterface interface {
+	Enrich(ctx context.Context, request models.EnrichmentRequest) (*models.Enrichment, error)
+}
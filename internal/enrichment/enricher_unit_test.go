@@ -0,0 +1,158 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLocalizedEnrichment(t *testing.T) {
+	var ageCountryIds, genderCountryIds []string
+	var countryRequests int
+
+	ageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ageCountryIds = append(ageCountryIds, r.URL.Query().Get("country_id"))
+		w.Write([]byte(`{"count":120,"name":"Dmitriy","age":41}`))
+	}))
+	defer ageServer.Close()
+
+	genderServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		genderCountryIds = append(genderCountryIds, r.URL.Query().Get("country_id"))
+		w.Write([]byte(`{"count":300,"name":"Dmitriy","gender":"male","probability":0.99}`))
+	}))
+	defer genderServer.Close()
+
+	countryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countryRequests++
+		w.Write([]byte(`{"count":1295,"name":"Dmitriy","country":[{"country_id":"RU","probability":0.5},{"country_id":"UA","probability":0.3}]}`))
+	}))
+	defer countryServer.Close()
+
+	newEnricher := func(defaultCountry string, localized bool) *Enricher {
+		enricher := NewEnricherWithProviders(
+			NewAgifyProvider(ageServer.URL+"/?name=", "", nil),
+			NewGenderizeProvider(genderServer.URL+"/?name=", "", nil),
+			NewNationalizeProvider(countryServer.URL+"/?name=", "", nil),
+		)
+		enricher.defaultCountry = defaultCountry
+		enricher.localized = localized
+		return enricher
+	}
+
+	ctx := context.Background()
+
+	t.Run("Enrich passes country hint", func(t *testing.T) {
+		ageCountryIds, genderCountryIds, countryRequests = nil, nil, 0
+
+		_, err := newEnricher("", false).Enrich(ctx, models.EnrichmentRequest{Name: "Dmitriy", CountryId: "KZ"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"KZ"}, ageCountryIds)
+		assert.Equal(t, []string{"KZ"}, genderCountryIds)
+	})
+
+	t.Run("Enrich uses default country", func(t *testing.T) {
+		ageCountryIds, genderCountryIds, countryRequests = nil, nil, 0
+
+		_, err := newEnricher("BY", false).Enrich(ctx, models.EnrichmentRequest{Name: "Dmitriy"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"BY"}, ageCountryIds)
+		assert.Equal(t, []string{"BY"}, genderCountryIds)
+	})
+
+	t.Run("Enrich resolves country first in localized mode", func(t *testing.T) {
+		ageCountryIds, genderCountryIds, countryRequests = nil, nil, 0
+
+		enriched, err := newEnricher("BY", true).Enrich(ctx, models.EnrichmentRequest{Name: "Dmitriy"})
+		require.NoError(t, err)
+		assert.Equal(t, "RU", enriched.Country)
+		assert.Equal(t, []string{"RU"}, ageCountryIds)
+		assert.Equal(t, []string{"RU"}, genderCountryIds)
+		assert.Equal(t, 1, countryRequests)
+	})
+}
+
+func TestEnrichCancellation(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			w.Write([]byte(`{"count":300,"name":"Anna","gender":"female","probability":0.98}`))
+		}
+	}))
+	defer slowServer.Close()
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	t.Run("Enrich cancels siblings on first failure", func(t *testing.T) {
+		enricher := NewEnricherWithProviders(
+			NewAgifyProvider(failingServer.URL+"/?name=", "", nil),
+			NewGenderizeProvider(slowServer.URL+"/?name=", "", nil),
+			NewNationalizeProvider(slowServer.URL+"/?name=", "", nil),
+		)
+
+		start := time.Now()
+		_, err := enricher.Enrich(context.Background(), models.EnrichmentRequest{Name: "Anna"})
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, err, custom_errors.ErrGetAgeStatusCode)
+
+		var enrichmentErr *custom_errors.EnrichmentError
+		require.ErrorAs(t, err, &enrichmentErr)
+		require.Len(t, enrichmentErr.Failures, 1)
+		assert.Equal(t, models.FieldAge, enrichmentErr.Failures[0].Field)
+		assert.Equal(t, ProviderAgify, enrichmentErr.Failures[0].Provider)
+	})
+
+	t.Run("Enrich reports deadline", func(t *testing.T) {
+		enricher := NewEnricherWithProviders(
+			NewAgifyProvider(slowServer.URL+"/?name=", "", nil),
+			NewGenderizeProvider(slowServer.URL+"/?name=", "", nil),
+			NewNationalizeProvider(slowServer.URL+"/?name=", "", nil),
+		)
+		enricher.timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		_, err := enricher.Enrich(context.Background(), models.EnrichmentRequest{Name: "Anna"})
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, err, custom_errors.ErrEnrichmentTimeout)
+	})
+}
+
+func TestEnrichPropagatesRequestContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent, requestId string
+	ageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		requestId = r.Header.Get(requestid.Header)
+		w.Write([]byte(`{"count":120,"name":"Dmitriy","age":41}`))
+	}))
+	defer ageServer.Close()
+
+	traceId, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanId, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	ctx := trace.ContextWithRemoteSpanContext(requestid.NewContext(context.Background(), "gateway-42"), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	_, err = NewAgifyProvider(ageServer.URL+"/?name=", "", nil).Estimate(ctx, models.EnrichmentRequest{Name: "Dmitriy"})
+	require.NoError(t, err)
+	assert.Contains(t, traceparent, traceId.String())
+	assert.Equal(t, "gateway-42", requestId)
+}
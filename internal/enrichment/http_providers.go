@@ -0,0 +1,349 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/metrics"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/redact"
+	"effective-mobile/internal/requestid"
+	"effective-mobile/internal/tracing"
+	"encoding/json"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderAgify       = "agify"
+	ProviderGenderize   = "genderize"
+	ProviderNationalize = "nationalize"
+)
+
+// tracedClient records a client span for every enrichment API call and sends the W3C trace
+// context in its headers.
+var tracedClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+type apiErrors struct {
+	statusCode    *custom_errors.InternalError
+	readBody      *custom_errors.InternalError
+	unmarshalBody *custom_errors.InternalError
+}
+
+// apiClient holds the request plumbing shared by the agify-like HTTP APIs.
+type apiClient struct {
+	provider string
+	baseUrl  string
+	apiKey   string
+	client   *http.Client
+	quota    *QuotaTracker
+	errors   apiErrors
+	inflight *coalescer[[]byte]
+}
+
+func newApiClient(provider, baseUrl, apiKey string, quota *QuotaTracker, errors apiErrors) apiClient {
+	if quota != nil {
+		quota.Register(provider, apiKey != "")
+	}
+	return apiClient{
+		provider: provider,
+		baseUrl:  baseUrl,
+		apiKey:   apiKey,
+		client:   tracedClient,
+		quota:    quota,
+		errors:   errors,
+		inflight: newCoalescer[[]byte](),
+	}
+}
+
+func (c *apiClient) fetch(ctx context.Context, name string, countryId string, target any) (err error) {
+	ctx, span := tracing.Start(ctx, "Enrichment."+c.provider, attribute.String("enrichment.provider", c.provider))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	key := models.NormalizeName(name) + "|" + countryId
+	body, shared, err := c.inflight.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+		startedAt := time.Now()
+		body, err := c.request(ctx, name, countryId)
+		if err != nil && ctx.Err() != nil {
+			// Every caller gave up, the provider itself did not fail.
+			metrics.ObserveProviderRequest(c.provider, time.Since(startedAt), ctx.Err())
+		} else {
+			metrics.ObserveProviderRequest(c.provider, time.Since(startedAt), err)
+		}
+		return body, err
+	})
+	metrics.CountProviderCall(c.provider, shared)
+	span.SetAttributes(attribute.Bool("enrichment.coalesced", shared))
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Ctx(ctx).Warn().
+				Err(ctx.Err()).
+				Str("provider", c.provider).
+				Msg("Enrichment API call abandoned by caller")
+			return ctx.Err()
+		}
+		return err
+	}
+
+	if shared {
+		log.Ctx(ctx).Debug().
+			Str("provider", c.provider).
+			Func(redact.Str(redact.FieldName, name)).
+			Msg("Reused in-flight enrichment API call")
+	}
+
+	if err = json.Unmarshal(body, target); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("provider", c.provider).
+			Func(redact.SensitiveStr("body", string(body))).
+			Msg(c.errors.unmarshalBody.Message)
+		return c.errors.unmarshalBody
+	}
+
+	return nil
+}
+
+func (c *apiClient) request(ctx context.Context, name string, countryId string) ([]byte, error) {
+	if c.quota != nil {
+		if exhausted, resetAt := c.quota.Exhausted(c.provider); exhausted {
+			log.Ctx(ctx).Warn().
+				Str("provider", c.provider).
+				Time("reset_at", resetAt).
+				Msg(custom_errors.ErrQuotaExhausted.Message)
+			return nil, custom_errors.ErrQuotaExhausted
+		}
+	}
+
+	requestUrl := c.baseUrl + url.QueryEscape(name)
+	if countryId != "" {
+		requestUrl += "&country_id=" + url.QueryEscape(countryId)
+	}
+	// Only keyedUrl carries the key, requestUrl is the one logged with sensitive debug logging.
+	keyedUrl := requestUrl
+	if c.apiKey != "" {
+		keyedUrl += "&apikey=" + url.QueryEscape(c.apiKey)
+	}
+	log.Ctx(ctx).Debug().
+		Str("provider", c.provider).
+		Func(redact.SensitiveStr("url", requestUrl)).
+		Msg("Making request to enrichment API")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyedUrl, nil)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("provider", c.provider).
+			Func(redact.SensitiveStr("url", requestUrl)).
+			Msg(custom_errors.ErrHttpGet.Message)
+		return nil, custom_errors.ErrHttpGet
+	}
+	// A coalesced call carries the request id of the caller that started it.
+	if requestId := requestid.FromContext(ctx); requestId != "" {
+		req.Header.Set(requestid.Header, requestId)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			// The query carries the name and the key, only the endpoint may reach the logs.
+			urlErr.URL, _, _ = strings.Cut(requestUrl, "?")
+		}
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("provider", c.provider).
+			Func(redact.SensitiveStr("url", requestUrl)).
+			Msg(custom_errors.ErrHttpGet.Message)
+		return nil, custom_errors.ErrHttpGet
+	}
+	defer resp.Body.Close()
+
+	log.Ctx(ctx).Debug().
+		Str("provider", c.provider).
+		Int("status_code", resp.StatusCode).
+		Msg("Enrichment API response received")
+
+	if c.quota != nil {
+		c.quota.Update(c.provider, resp.StatusCode, resp.Header)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		log.Ctx(ctx).Error().
+			Str("provider", c.provider).
+			Func(redact.SensitiveStr("url", requestUrl)).
+			Msg(custom_errors.ErrQuotaExhausted.Message)
+		return nil, custom_errors.ErrQuotaExhausted
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Ctx(ctx).Error().
+			Int("status_code", resp.StatusCode).
+			Str("provider", c.provider).
+			Func(redact.SensitiveStr("url", requestUrl)).
+			Msg(c.errors.statusCode.Message)
+		return nil, c.errors.statusCode
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("provider", c.provider).
+			Func(redact.SensitiveStr("url", requestUrl)).
+			Msg(c.errors.readBody.Message)
+		return nil, c.errors.readBody
+	}
+
+	return body, nil
+}
+
+type AgifyProvider struct {
+	api apiClient
+}
+
+func NewAgifyProvider(baseUrl string, apiKey string, quota *QuotaTracker) *AgifyProvider {
+	return &AgifyProvider{api: newApiClient(ProviderAgify, baseUrl, apiKey, quota, apiErrors{
+		statusCode:    custom_errors.ErrGetAgeStatusCode,
+		readBody:      custom_errors.ErrGetAgeReadBody,
+		unmarshalBody: custom_errors.ErrGetAgeUnmarshalBody,
+	})}
+}
+
+func (p *AgifyProvider) Name() string {
+	return ProviderAgify
+}
+
+func (p *AgifyProvider) Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[uint32], error) {
+	var ageDto dtos.AgeDto
+	if err := p.api.fetch(ctx, request.Name, request.CountryId, &ageDto); err != nil {
+		return nil, err
+	}
+
+	if ageDto.Age == nil {
+		log.Ctx(ctx).Error().
+			Func(redact.Str(redact.FieldName, request.Name)).
+			Msg(custom_errors.ErrGotNoAge.Message)
+		return nil, custom_errors.ErrGotNoAge
+	}
+
+	log.Ctx(ctx).Debug().
+		Func(redact.Uint32(redact.FieldAge, *ageDto.Age)).
+		Func(redact.Str(redact.FieldName, request.Name)).
+		Msg("Age successfully determined")
+
+	return &Estimate[uint32]{
+		Value:       *ageDto.Age,
+		Probability: countConfidence(ageDto.Count),
+		Count:       ageDto.Count,
+		Provider:    ProviderAgify,
+	}, nil
+}
+
+type GenderizeProvider struct {
+	api apiClient
+}
+
+func NewGenderizeProvider(baseUrl string, apiKey string, quota *QuotaTracker) *GenderizeProvider {
+	return &GenderizeProvider{api: newApiClient(ProviderGenderize, baseUrl, apiKey, quota, apiErrors{
+		statusCode:    custom_errors.ErrGetGenderStatusCode,
+		readBody:      custom_errors.ErrGetGenderReadBody,
+		unmarshalBody: custom_errors.ErrGetGenderUnmarshalBody,
+	})}
+}
+
+func (p *GenderizeProvider) Name() string {
+	return ProviderGenderize
+}
+
+func (p *GenderizeProvider) Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[models.GenderType], error) {
+	var genderDto dtos.GenderDto
+	if err := p.api.fetch(ctx, request.Name, request.CountryId, &genderDto); err != nil {
+		return nil, err
+	}
+
+	gender := models.GenderType(genderDto.Gender)
+	if gender != models.Male && gender != models.Female {
+		log.Ctx(ctx).Error().
+			Func(redact.Str(redact.FieldGender, string(gender))).
+			Func(redact.Str(redact.FieldName, request.Name)).
+			Msg(custom_errors.ErrGotInvalidGender.Message)
+		return nil, custom_errors.ErrGotInvalidGender
+	}
+
+	log.Ctx(ctx).Debug().
+		Func(redact.Str(redact.FieldGender, string(gender))).
+		Func(redact.Str(redact.FieldName, request.Name)).
+		Msg("Gender successfully determined")
+
+	return &Estimate[models.GenderType]{
+		Value:       gender,
+		Probability: genderDto.Probability,
+		Count:       genderDto.Count,
+		Provider:    ProviderGenderize,
+	}, nil
+}
+
+type NationalizeProvider struct {
+	api apiClient
+}
+
+func NewNationalizeProvider(baseUrl string, apiKey string, quota *QuotaTracker) *NationalizeProvider {
+	return &NationalizeProvider{api: newApiClient(ProviderNationalize, baseUrl, apiKey, quota, apiErrors{
+		statusCode:    custom_errors.ErrGetCountryStatusCode,
+		readBody:      custom_errors.ErrGetCountryReadBody,
+		unmarshalBody: custom_errors.ErrGetCountryUnmarshalBody,
+	})}
+}
+
+func (p *NationalizeProvider) Name() string {
+	return ProviderNationalize
+}
+
+func (p *NationalizeProvider) Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[string], error) {
+	var countryDto dtos.CountryDto
+	if err := p.api.fetch(ctx, request.Name, "", &countryDto); err != nil {
+		return nil, err
+	}
+
+	if len(countryDto.Countries) == 0 {
+		log.Ctx(ctx).Error().
+			Func(redact.Str(redact.FieldName, request.Name)).
+			Msg(custom_errors.ErrGotNoCountry.Message)
+		return nil, custom_errors.ErrGotNoCountry
+	}
+
+	sort.SliceStable(countryDto.Countries, func(i, j int) bool {
+		return countryDto.Countries[i].Probability > countryDto.Countries[j].Probability
+	})
+
+	candidates := make([]models.CountryCandidate, 0, len(countryDto.Countries))
+	for _, candidate := range countryDto.Countries {
+		candidates = append(candidates, models.CountryCandidate{
+			Country:     candidate.Id,
+			Probability: candidate.Probability,
+		})
+	}
+
+	log.Ctx(ctx).Debug().
+		Func(redact.Str(redact.FieldCountry, candidates[0].Country)).
+		Float64("probability", candidates[0].Probability).
+		Func(redact.Str(redact.FieldName, request.Name)).
+		Msg("Country successfully determined")
+
+	return &Estimate[string]{
+		Value:       candidates[0].Country,
+		Probability: candidates[0].Probability,
+		Count:       countryDto.Count,
+		Provider:    ProviderNationalize,
+		Candidates:  candidates,
+	}, nil
+}
@@ -0,0 +1,339 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/redact"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const ProviderLocal = "local"
+
+//go:embed dataset/names.csv
+var bundledDataset []byte
+
+var datasetHeader = []string{"name", "age_count", "mean_age", "male_count", "female_count", "countries"}
+
+// LocalDataset answers enrichment requests from name statistics kept in memory.
+type LocalDataset struct {
+	names map[string]models.NameStatistics
+}
+
+func NewLocalDataset(statistics []models.NameStatistics) *LocalDataset {
+	names := make(map[string]models.NameStatistics, len(statistics))
+	for _, stat := range statistics {
+		names[models.NormalizeName(stat.Name)] = stat
+	}
+	return &LocalDataset{names: names}
+}
+
+// LoadDataset reads the dataset from path, or the bundled one when path is empty.
+func LoadDataset(path string) (*LocalDataset, error) {
+	var reader io.Reader = bytes.NewReader(bundledDataset)
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("path", path).
+				Msg(custom_errors.ErrLoadDataset.Message)
+			return nil, custom_errors.ErrLoadDataset
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	statistics, err := ReadDataset(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("path", path).
+		Int("names_count", len(statistics)).
+		Msg("Local name dataset loaded")
+
+	return NewLocalDataset(statistics), nil
+}
+
+func ReadDataset(reader io.Reader) ([]models.NameStatistics, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrParseDataset.Message)
+		return nil, custom_errors.ErrParseDataset
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	statistics := make([]models.NameStatistics, 0, len(records)-1)
+	for i, record := range records[1:] {
+		stat, err := parseDatasetRecord(record)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int("line", i+2).
+				Msg(custom_errors.ErrParseDataset.Message)
+			return nil, custom_errors.ErrParseDataset
+		}
+		statistics = append(statistics, stat)
+	}
+
+	return statistics, nil
+}
+
+func WriteDataset(writer io.Writer, statistics []models.NameStatistics) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(datasetHeader); err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrWriteDataset.Message)
+		return custom_errors.ErrWriteDataset
+	}
+
+	for _, stat := range statistics {
+		record := []string{
+			models.NormalizeName(stat.Name),
+			strconv.Itoa(stat.AgeCount),
+			strconv.FormatFloat(stat.MeanAge, 'f', 1, 64),
+			strconv.Itoa(stat.MaleCount),
+			strconv.Itoa(stat.FemaleCount),
+			formatCountries(stat.Countries),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			log.Error().
+				Err(err).
+				Msg(custom_errors.ErrWriteDataset.Message)
+			return custom_errors.ErrWriteDataset
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		log.Error().
+			Err(err).
+			Msg(custom_errors.ErrWriteDataset.Message)
+		return custom_errors.ErrWriteDataset
+	}
+
+	return nil
+}
+
+func parseDatasetRecord(record []string) (models.NameStatistics, error) {
+	if len(record) != len(datasetHeader) {
+		return models.NameStatistics{}, fmt.Errorf("expected %d columns, got %d", len(datasetHeader), len(record))
+	}
+
+	stat := models.NameStatistics{Name: record[0]}
+	var err error
+	if stat.AgeCount, err = strconv.Atoi(record[1]); err != nil {
+		return stat, err
+	}
+	if stat.MeanAge, err = strconv.ParseFloat(record[2], 64); err != nil {
+		return stat, err
+	}
+	if stat.MaleCount, err = strconv.Atoi(record[3]); err != nil {
+		return stat, err
+	}
+	if stat.FemaleCount, err = strconv.Atoi(record[4]); err != nil {
+		return stat, err
+	}
+	if stat.Countries, err = parseCountries(record[5]); err != nil {
+		return stat, err
+	}
+
+	return stat, nil
+}
+
+// parseCountries reads country counts written as "RU:120;UA:40".
+func parseCountries(value string) (map[string]int, error) {
+	countries := make(map[string]int)
+	if value == "" {
+		return countries, nil
+	}
+
+	for _, pair := range strings.Split(value, ";") {
+		country, count, found := strings.Cut(pair, ":")
+		if !found {
+			return nil, errors.New("invalid country count " + pair)
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, err
+		}
+		countries[country] = n
+	}
+
+	return countries, nil
+}
+
+func formatCountries(countries map[string]int) string {
+	pairs := make([]string, 0, len(countries))
+	for _, candidate := range sortCountries(countries) {
+		pairs = append(pairs, candidate.country+":"+strconv.Itoa(candidate.count))
+	}
+	return strings.Join(pairs, ";")
+}
+
+type countryCount struct {
+	country string
+	count   int
+}
+
+func sortCountries(countries map[string]int) []countryCount {
+	sorted := make([]countryCount, 0, len(countries))
+	for country, count := range countries {
+		sorted = append(sorted, countryCount{country: country, count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].country < sorted[j].country
+	})
+	return sorted
+}
+
+func (d *LocalDataset) lookup(name string) (models.NameStatistics, error) {
+	stat, ok := d.names[models.NormalizeName(name)]
+	if !ok {
+		log.Debug().
+			Func(redact.Str(redact.FieldName, name)).
+			Msg(custom_errors.ErrNameNotInDataset.Message)
+		return stat, custom_errors.ErrNameNotInDataset
+	}
+	return stat, nil
+}
+
+// Statistics returns the statistics kept for name, if any.
+func (d *LocalDataset) Statistics(name string) (models.NameStatistics, bool) {
+	stat, ok := d.names[models.NormalizeName(name)]
+	return stat, ok
+}
+
+func (d *LocalDataset) AgeProvider() AgeProvider {
+	return &localAgeProvider{dataset: d}
+}
+
+func (d *LocalDataset) GenderProvider() GenderProvider {
+	return &localGenderProvider{dataset: d}
+}
+
+func (d *LocalDataset) CountryProvider() CountryProvider {
+	return &localCountryProvider{dataset: d}
+}
+
+type localAgeProvider struct {
+	dataset *LocalDataset
+}
+
+func (p *localAgeProvider) Name() string {
+	return ProviderLocal
+}
+
+func (p *localAgeProvider) Estimate(_ context.Context, request models.EnrichmentRequest) (*Estimate[uint32], error) {
+	stat, err := p.dataset.lookup(request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if stat.AgeCount == 0 {
+		return nil, custom_errors.ErrGotNoAge
+	}
+
+	return &Estimate[uint32]{
+		Value:       uint32(math.Round(stat.MeanAge)),
+		Probability: countConfidence(stat.AgeCount),
+		Count:       stat.AgeCount,
+		Provider:    ProviderLocal,
+	}, nil
+}
+
+type localGenderProvider struct {
+	dataset *LocalDataset
+}
+
+func (p *localGenderProvider) Name() string {
+	return ProviderLocal
+}
+
+func (p *localGenderProvider) Estimate(_ context.Context, request models.EnrichmentRequest) (*Estimate[models.GenderType], error) {
+	stat, err := p.dataset.lookup(request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	total := stat.MaleCount + stat.FemaleCount
+	if total == 0 {
+		return nil, custom_errors.ErrGotInvalidGender
+	}
+
+	estimate := &Estimate[models.GenderType]{
+		Value:       models.Male,
+		Probability: float64(stat.MaleCount) / float64(total),
+		Count:       total,
+		Provider:    ProviderLocal,
+	}
+	if stat.FemaleCount > stat.MaleCount {
+		estimate.Value = models.Female
+		estimate.Probability = float64(stat.FemaleCount) / float64(total)
+	}
+
+	return estimate, nil
+}
+
+type localCountryProvider struct {
+	dataset *LocalDataset
+}
+
+func (p *localCountryProvider) Name() string {
+	return ProviderLocal
+}
+
+func (p *localCountryProvider) Estimate(_ context.Context, request models.EnrichmentRequest) (*Estimate[string], error) {
+	stat, err := p.dataset.lookup(request.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, count := range stat.Countries {
+		total += count
+	}
+	if total == 0 {
+		return nil, custom_errors.ErrGotNoCountry
+	}
+
+	sorted := sortCountries(stat.Countries)
+	candidates := make([]models.CountryCandidate, 0, len(sorted))
+	for _, candidate := range sorted {
+		candidates = append(candidates, models.CountryCandidate{
+			Country:     candidate.country,
+			Probability: float64(candidate.count) / float64(total),
+		})
+	}
+
+	return &Estimate[string]{
+		Value:       candidates[0].Country,
+		Probability: candidates[0].Probability,
+		Count:       total,
+		Provider:    ProviderLocal,
+		Candidates:  candidates,
+	}, nil
+}
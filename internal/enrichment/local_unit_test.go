@@ -0,0 +1,102 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDatasetRoundTrip(t *testing.T) {
+	statistics := []models.NameStatistics{
+		{
+			Name:        "Dmitriy",
+			AgeCount:    10,
+			MeanAge:     43.6,
+			MaleCount:   9,
+			FemaleCount: 1,
+			Countries:   map[string]int{"RU": 6, "UA": 3},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDataset(&buf, statistics))
+	assert.Contains(t, buf.String(), "dmitriy,10,43.6,9,1,RU:6;UA:3")
+
+	read, err := ReadDataset(&buf)
+	require.NoError(t, err)
+	require.Len(t, read, 1)
+	assert.Equal(t, "dmitriy", read[0].Name)
+	assert.Equal(t, statistics[0].Countries, read[0].Countries)
+}
+
+func TestLoadBundledDataset(t *testing.T) {
+	dataset, err := LoadDataset("")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	request := models.EnrichmentRequest{Name: "Dmitriy"}
+
+	age, err := dataset.AgeProvider().Estimate(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderLocal, age.Provider)
+	assert.NotZero(t, age.Value)
+
+	gender, err := dataset.GenderProvider().Estimate(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, models.Male, gender.Value)
+
+	country, err := dataset.CountryProvider().Estimate(ctx, models.EnrichmentRequest{Name: "ДМИТРИЙ"})
+	require.NoError(t, err)
+	assert.Equal(t, "RU", country.Value)
+	assert.NotEmpty(t, country.Candidates)
+
+	_, err = dataset.AgeProvider().Estimate(ctx, models.EnrichmentRequest{Name: "Unknown"})
+	assert.Equal(t, custom_errors.ErrNameNotInDataset, err)
+}
+
+func TestLocalFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	dataset := NewLocalDataset([]models.NameStatistics{
+		{Name: "anna", AgeCount: 2, MeanAge: 30, MaleCount: 0, FemaleCount: 2, Countries: map[string]int{"PL": 2}},
+	})
+
+	enricher := NewEnricherWithProviders(
+		NewFallbackProvider(NewAgifyProvider(server.URL+"/?name=", "", nil), dataset.AgeProvider()),
+		NewFallbackProvider(NewGenderizeProvider(server.URL+"/?name=", "", nil), dataset.GenderProvider()),
+		NewFallbackProvider(NewNationalizeProvider(server.URL+"/?name=", "", nil), dataset.CountryProvider()),
+	)
+
+	enriched, err := enricher.Enrich(context.Background(), models.EnrichmentRequest{Name: "Anna"})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(30), enriched.Age)
+	assert.Equal(t, ProviderLocal, enriched.AgeProvider)
+	assert.Equal(t, models.Female, enriched.Gender)
+	assert.Equal(t, float64(1), enriched.GenderProbability)
+	assert.Equal(t, "PL", enriched.Country)
+
+	require.Len(t, enriched.Results, 6)
+	assert.Equal(t, models.FieldAge, enriched.Results[0].Field)
+	assert.Equal(t, ProviderAgify, enriched.Results[0].Provider)
+	assert.NotEmpty(t, enriched.Results[0].Error)
+	assert.Equal(t, ProviderLocal, enriched.Results[1].Provider)
+	assert.True(t, enriched.Results[1].Chosen)
+
+	t.Run("Enrich with unknown name", func(t *testing.T) {
+		_, err := enricher.Enrich(context.Background(), models.EnrichmentRequest{Name: "Olga"})
+		assert.ErrorIs(t, err, custom_errors.ErrNameNotInDataset)
+
+		var enrichmentErr *custom_errors.EnrichmentError
+		require.ErrorAs(t, err, &enrichmentErr)
+		assert.NotEmpty(t, enrichmentErr.Failures)
+	})
+}
@@ -0,0 +1,67 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"fmt"
+)
+
+type Estimate[T comparable] struct {
+	Value       T
+	Probability float64
+	Count       int
+	Provider    string
+
+	// Candidates holds the alternatives reported by the provider, most probable first.
+	Candidates []models.CountryCandidate
+
+	// Considered lists the provider answers a chain or a vote looked at, including this one.
+	Considered []models.ProviderResult
+}
+
+type Provider[T comparable] interface {
+	Name() string
+	Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[T], error)
+}
+
+type (
+	AgeProvider     = Provider[uint32]
+	GenderProvider  = Provider[models.GenderType]
+	CountryProvider = Provider[string]
+)
+
+// countConfidenceScale is the sample size at which a count-based confidence reaches 0.5.
+const countConfidenceScale = 50
+
+// countConfidence turns the size of a provider's sample into a confidence for providers that
+// do not report a probability, such as agify.
+func countConfidence(count int) float64 {
+	if count <= 0 {
+		return 0
+	}
+	return float64(count) / float64(count+countConfidenceScale)
+}
+
+func (e *Estimate[T]) result(chosen bool) models.ProviderResult {
+	return models.ProviderResult{
+		Provider:   e.Provider,
+		Value:      fmt.Sprint(e.Value),
+		Confidence: e.Probability,
+		Chosen:     chosen,
+	}
+}
+
+// considered returns the audit trail of the estimate, marking it as chosen when nothing else did.
+func (e *Estimate[T]) considered() []models.ProviderResult {
+	if len(e.Considered) > 0 {
+		return e.Considered
+	}
+	return []models.ProviderResult{e.result(true)}
+}
+
+func failedResult(provider string, err error) models.ProviderResult {
+	return models.ProviderResult{
+		Provider: provider,
+		Error:    err.Error(),
+	}
+}
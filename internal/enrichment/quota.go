@@ -0,0 +1,111 @@
+package enrichment
+
+import (
+	"effective-mobile/internal/models"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitLimit     = "X-Rate-Limit-Limit"
+	headerRateLimitRemaining = "X-Rate-Limit-Remaining"
+	headerRateLimitReset     = "X-Rate-Limit-Reset"
+)
+
+// QuotaTracker remembers the rate limit headers returned by the HTTP providers.
+type QuotaTracker struct {
+	mu     sync.RWMutex
+	quotas map[string]models.ProviderQuota
+	now    func() time.Time
+}
+
+func NewQuotaTracker() *QuotaTracker {
+	log.Debug().Msg("Initializing QuotaTracker")
+	return &QuotaTracker{quotas: make(map[string]models.ProviderQuota), now: time.Now}
+}
+
+// Register makes the provider visible in Quotas before its first response.
+func (t *QuotaTracker) Register(provider string, apiKeyConfigured bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quota := t.quotas[provider]
+	quota.Provider = provider
+	quota.ApiKeyConfigured = apiKeyConfigured
+	t.quotas[provider] = quota
+}
+
+// Update records the rate limit headers of a response. Responses without them are ignored.
+func (t *QuotaTracker) Update(provider string, statusCode int, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get(headerRateLimitRemaining))
+	if err != nil {
+		if statusCode != http.StatusTooManyRequests {
+			return
+		}
+		remaining = 0
+	}
+
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quota := t.quotas[provider]
+	quota.Provider = provider
+	quota.Known = true
+	quota.Remaining = remaining
+	quota.UpdatedAt = now
+	if statusCode == http.StatusTooManyRequests {
+		quota.Remaining = 0
+	}
+	if limit, err := strconv.Atoi(header.Get(headerRateLimitLimit)); err == nil {
+		quota.Limit = limit
+	}
+	if reset, err := strconv.Atoi(header.Get(headerRateLimitReset)); err == nil {
+		quota.ResetAt = now.Add(time.Duration(reset) * time.Second)
+	} else if quota.ResetAt.Before(now) {
+		// Without a reset hint the free tier renews daily.
+		quota.ResetAt = now.Add(24 * time.Hour)
+	}
+	t.quotas[provider] = quota
+
+	log.Debug().
+		Str("provider", provider).
+		Int("limit", quota.Limit).
+		Int("remaining", quota.Remaining).
+		Time("reset_at", quota.ResetAt).
+		Msg("Provider quota updated")
+
+	if quota.Remaining <= 0 {
+		log.Warn().
+			Str("provider", provider).
+			Time("reset_at", quota.ResetAt).
+			Msg("Provider quota exhausted")
+	}
+}
+
+func (t *QuotaTracker) Exhausted(provider string) (bool, time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	quota := t.quotas[provider]
+	return quota.IsExhausted(t.now()), quota.ResetAt
+}
+
+func (t *QuotaTracker) Quotas() []models.ProviderQuota {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	quotas := make([]models.ProviderQuota, 0, len(t.quotas))
+	for _, quota := range t.quotas {
+		quotas = append(quotas, quota)
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Provider < quotas[j].Provider
+	})
+	return quotas
+}
@@ -0,0 +1,7 @@
+package enrichment
+
+import "effective-mobile/internal/models"
+
+type QuotaTrackerInterface interface {
+	Quotas() []models.ProviderQuota
+}
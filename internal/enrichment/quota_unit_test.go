@@ -0,0 +1,73 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuotaTracking(t *testing.T) {
+	var requests int
+	var apiKey string
+	remaining := "1"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		apiKey = r.URL.Query().Get("apikey")
+		w.Header().Set(headerRateLimitLimit, "100")
+		w.Header().Set(headerRateLimitRemaining, remaining)
+		w.Header().Set(headerRateLimitReset, "3600")
+		w.Write([]byte(`{"count":300,"name":"Anna","gender":"female","probability":0.98}`))
+	}))
+	defer server.Close()
+
+	quota := NewQuotaTracker()
+	genderize := NewGenderizeProvider(server.URL+"/?name=", "secret", quota)
+	dataset := NewLocalDataset([]models.NameStatistics{{Name: "anna", FemaleCount: 3}})
+	provider := NewFallbackProvider(GenderProvider(genderize), dataset.GenderProvider())
+
+	ctx := context.Background()
+	request := models.EnrichmentRequest{Name: "Anna"}
+
+	estimate, err := provider.Estimate(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderGenderize, estimate.Provider)
+	assert.Equal(t, "secret", apiKey)
+
+	quotas := quota.Quotas()
+	require.Len(t, quotas, 1)
+	assert.True(t, quotas[0].ApiKeyConfigured)
+	assert.Equal(t, 100, quotas[0].Limit)
+	assert.Equal(t, 1, quotas[0].Remaining)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), quotas[0].ResetAt, time.Minute)
+
+	t.Run("Exhausted provider is not called", func(t *testing.T) {
+		remaining = "0"
+		_, err := provider.Estimate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+
+		exhausted, _ := quota.Exhausted(ProviderGenderize)
+		assert.True(t, exhausted)
+
+		_, err = genderize.Estimate(ctx, request)
+		assert.Equal(t, custom_errors.ErrQuotaExhausted, err)
+		assert.Equal(t, 2, requests)
+
+		estimate, err := provider.Estimate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, ProviderLocal, estimate.Provider)
+	})
+
+	t.Run("Quota renews after reset", func(t *testing.T) {
+		quota.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		exhausted, _ := quota.Exhausted(ProviderGenderize)
+		assert.False(t, exhausted)
+	})
+}
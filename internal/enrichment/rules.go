@@ -0,0 +1,116 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/redact"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+const ProviderRules = "rules"
+
+const (
+	patronymicConfidence      = 0.99
+	surnameConfidence         = 0.9
+	conflictConfidencePenalty = 0.1
+)
+
+type genderRule struct {
+	suffix     string
+	gender     models.GenderType
+	confidence float64
+}
+
+// Longer suffixes go first so that "-овна" is not taken for "-на" and so on. Only Cyrillic
+// endings are matched: transliterated ones such as "-in" or "-ina" end many Western names too.
+var patronymicRules = []genderRule{
+	{"овна", models.Female, patronymicConfidence},
+	{"евна", models.Female, patronymicConfidence},
+	{"ична", models.Female, patronymicConfidence},
+	{"кызы", models.Female, patronymicConfidence},
+	{"ович", models.Male, patronymicConfidence},
+	{"евич", models.Male, patronymicConfidence},
+	{"ич", models.Male, patronymicConfidence},
+	{"оглы", models.Male, patronymicConfidence},
+}
+
+var surnameRules = []genderRule{
+	{"ская", models.Female, surnameConfidence},
+	{"цкая", models.Female, surnameConfidence},
+	{"ова", models.Female, surnameConfidence},
+	{"ева", models.Female, surnameConfidence},
+	{"ёва", models.Female, surnameConfidence},
+	{"ина", models.Female, surnameConfidence},
+	{"ына", models.Female, surnameConfidence},
+	{"ский", models.Male, surnameConfidence},
+	{"цкий", models.Male, surnameConfidence},
+	{"ской", models.Male, surnameConfidence},
+	{"ов", models.Male, surnameConfidence},
+	{"ев", models.Male, surnameConfidence},
+	{"ёв", models.Male, surnameConfidence},
+	{"ин", models.Male, surnameConfidence},
+	{"ын", models.Male, surnameConfidence},
+}
+
+// RulesGenderProvider infers gender from the endings of Russian patronymics and surnames
+// written in Cyrillic.
+type RulesGenderProvider struct{}
+
+func NewRulesGenderProvider() *RulesGenderProvider {
+	return &RulesGenderProvider{}
+}
+
+func (p *RulesGenderProvider) Name() string {
+	return ProviderRules
+}
+
+func (p *RulesGenderProvider) Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[models.GenderType], error) {
+	patronymic, hasPatronymic := matchGenderRule(patronymicRules, request.Patronymic)
+	surname, hasSurname := matchGenderRule(surnameRules, request.Surname)
+
+	var rule genderRule
+	switch {
+	case hasPatronymic && hasSurname && patronymic.gender != surname.gender:
+		rule = patronymic
+		rule.confidence -= conflictConfidencePenalty
+	case hasPatronymic:
+		rule = patronymic
+	case hasSurname:
+		rule = surname
+	default:
+		log.Ctx(ctx).Debug().
+			Func(redact.Str(redact.FieldSurname, request.Surname)).
+			Func(redact.Str(redact.FieldPatronymic, request.Patronymic)).
+			Msg(custom_errors.ErrGenderNotInferred.Message)
+		return nil, custom_errors.ErrGenderNotInferred
+	}
+
+	log.Ctx(ctx).Debug().
+		Func(redact.Str(redact.FieldGender, string(rule.gender))).
+		Str("suffix", rule.suffix).
+		Float64("confidence", rule.confidence).
+		Msg("Gender inferred by rules")
+
+	return &Estimate[models.GenderType]{
+		Value:       rule.gender,
+		Probability: rule.confidence,
+		Provider:    ProviderRules,
+	}, nil
+}
+
+func matchGenderRule(rules []genderRule, value string) (genderRule, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return genderRule{}, false
+	}
+
+	for _, rule := range rules {
+		// A bare suffix is not a name.
+		if strings.HasSuffix(value, rule.suffix) && len(value) > len(rule.suffix) {
+			return rule, true
+		}
+	}
+	return genderRule{}, false
+}
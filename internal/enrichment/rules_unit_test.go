@@ -0,0 +1,67 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRulesGenderProvider(t *testing.T) {
+	provider := NewRulesGenderProvider()
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		surname    string
+		patronymic string
+		gender     models.GenderType
+		confidence float64
+	}{
+		{"Cyrillic male patronymic", "", "Васильевич", models.Male, patronymicConfidence},
+		{"Cyrillic female patronymic", "", "Ильинична", models.Female, patronymicConfidence},
+		{"Cyrillic male surname", "Ушаков", "", models.Male, surnameConfidence},
+		{"Cyrillic female surname", "Достоевская", "", models.Female, surnameConfidence},
+		{"Patronymic wins over surname", "Иванова", "Петрович", models.Male, patronymicConfidence - conflictConfidencePenalty},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			estimate, err := provider.Estimate(ctx, models.EnrichmentRequest{
+				Name:       "Name",
+				Surname:    test.surname,
+				Patronymic: test.patronymic,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.gender, estimate.Value)
+			assert.InDelta(t, test.confidence, estimate.Probability, 1e-9)
+			assert.Equal(t, ProviderRules, estimate.Provider)
+		})
+	}
+
+	t.Run("Estimate without known endings", func(t *testing.T) {
+		estimate, err := provider.Estimate(ctx, models.EnrichmentRequest{Name: "John", Surname: "Smith"})
+		assert.Nil(t, estimate)
+		assert.Equal(t, custom_errors.ErrGenderNotInferred, err)
+	})
+
+	// Transliterated endings are left to genderize, they would misgender these.
+	for _, request := range []models.EnrichmentRequest{
+		{Name: "Steve", Surname: "Martin"},
+		{Name: "Paul", Surname: "Austin"},
+		{Name: "Aretha", Surname: "Franklin"},
+		{Name: "Maria", Surname: "Carolina"},
+		{Name: "Eva", Surname: "Katarina"},
+		{Name: "Robert", Surname: "Aldrich"},
+		{Name: "Kevin", Surname: "Kevin"},
+		{Name: "Anna", Surname: "Ivanova", Patronymic: "Petrovna"},
+	} {
+		t.Run("Estimate with Latin surname "+request.Surname, func(t *testing.T) {
+			estimate, err := provider.Estimate(ctx, request)
+			assert.Nil(t, estimate)
+			assert.Equal(t, custom_errors.ErrGenderNotInferred, err)
+		})
+	}
+}
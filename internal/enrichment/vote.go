@@ -0,0 +1,180 @@
+package enrichment
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/rs/zerolog/log"
+	"math"
+	"sync"
+)
+
+type ballot[T comparable] struct {
+	estimate *Estimate[T]
+	score    float64
+}
+
+type voteProvider[T comparable] struct {
+	providers     []Provider[T]
+	weights       map[string]float64
+	minConfidence float64
+	count         func(ballots []ballot[T]) *Estimate[T]
+}
+
+// NewMajorityVoteProvider asks all providers and picks the value with the highest sum of
+// weight * confidence. Providers missing from weights get weight 1.
+func NewMajorityVoteProvider[T comparable](weights map[string]float64, minConfidence float64, providers ...Provider[T]) Provider[T] {
+	return &voteProvider[T]{providers: providers, weights: weights, minConfidence: minConfidence, count: countMajority[T]}
+}
+
+// NewMeanAgeVoteProvider asks all providers and returns the mean age weighted by weight * confidence.
+func NewMeanAgeVoteProvider(weights map[string]float64, minConfidence float64, providers ...AgeProvider) AgeProvider {
+	return &voteProvider[uint32]{providers: providers, weights: weights, minConfidence: minConfidence, count: countMeanAge}
+}
+
+func (p *voteProvider[T]) Name() string {
+	return joinProviderNames(p.providers)
+}
+
+func (p *voteProvider[T]) Estimate(ctx context.Context, request models.EnrichmentRequest) (*Estimate[T], error) {
+	estimates := make([]*Estimate[T], len(p.providers))
+	errs := make([]error, len(p.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range p.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			estimates[i], errs[i] = provider.Estimate(ctx, request)
+		}()
+	}
+	wg.Wait()
+
+	considered := make([]models.ProviderResult, 0, len(p.providers))
+	ballots := make([]ballot[T], 0, len(p.providers))
+	var lastErr error = custom_errors.ErrNoConfidentEstimate
+
+	for i, provider := range p.providers {
+		if errs[i] != nil {
+			log.Ctx(ctx).Warn().
+				Err(errs[i]).
+				Str("provider", provider.Name()).
+				Msg("Provider failed, leaving it out of the vote")
+			considered = append(considered, failedResult(provider.Name(), errs[i]))
+			lastErr = errs[i]
+			continue
+		}
+
+		considered = append(considered, estimates[i].result(false))
+		if estimates[i].Probability < p.minConfidence {
+			log.Ctx(ctx).Debug().
+				Str("provider", provider.Name()).
+				Float64("confidence", estimates[i].Probability).
+				Msg("Provider confidence below threshold, leaving it out of the vote")
+			continue
+		}
+
+		ballots = append(ballots, ballot[T]{estimate: estimates[i], score: p.weight(provider.Name()) * estimates[i].Probability})
+	}
+
+	if len(ballots) == 0 {
+		return nil, lastErr
+	}
+
+	// Providers that report no confidence at all still get a say proportional to their weight.
+	if totalScore(ballots) == 0 {
+		for i := range ballots {
+			ballots[i].score = p.weight(ballots[i].estimate.Provider)
+		}
+	}
+	// Only providers weighted 0 answered, there is nothing to divide the scores by.
+	if totalScore(ballots) == 0 {
+		log.Ctx(ctx).Warn().
+			Int("ballots", len(ballots)).
+			Msg("Every answering provider has weight 0, the vote has no result")
+		return nil, custom_errors.ErrNoConfidentEstimate
+	}
+
+	estimate := p.count(ballots)
+	for i := range considered {
+		if considered[i].Provider == estimate.Provider && considered[i].Error == "" {
+			considered[i].Chosen = true
+		}
+	}
+	estimate.Considered = considered
+
+	log.Ctx(ctx).Debug().
+		Str("provider", estimate.Provider).
+		Float64("confidence", estimate.Probability).
+		Int("ballots", len(ballots)).
+		Msg("Vote completed")
+
+	return estimate, nil
+}
+
+func (p *voteProvider[T]) weight(provider string) float64 {
+	if weight, ok := p.weights[provider]; ok {
+		return weight
+	}
+	return 1
+}
+
+func totalScore[T comparable](ballots []ballot[T]) float64 {
+	total := 0.0
+	for _, b := range ballots {
+		total += b.score
+	}
+	return total
+}
+
+func countMajority[T comparable](ballots []ballot[T]) *Estimate[T] {
+	scores := make(map[T]float64)
+	for _, b := range ballots {
+		scores[b.estimate.Value] += b.score
+	}
+
+	var winner *ballot[T]
+	for i, b := range ballots {
+		if winner == nil || scores[b.estimate.Value] > scores[winner.estimate.Value] ||
+			(b.estimate.Value == winner.estimate.Value && b.score > winner.score) {
+			winner = &ballots[i]
+		}
+	}
+
+	count := 0
+	for _, b := range ballots {
+		if b.estimate.Value == winner.estimate.Value {
+			count += b.estimate.Count
+		}
+	}
+
+	return &Estimate[T]{
+		Value:       winner.estimate.Value,
+		Probability: scores[winner.estimate.Value] / totalScore(ballots),
+		Count:       count,
+		Provider:    winner.estimate.Provider,
+		Candidates:  winner.estimate.Candidates,
+	}
+}
+
+func countMeanAge(ballots []ballot[uint32]) *Estimate[uint32] {
+	total := totalScore(ballots)
+	sum, confidence := 0.0, 0.0
+	count := 0
+	best := ballots[0]
+	for _, b := range ballots {
+		sum += float64(b.estimate.Value) * b.score
+		confidence += b.estimate.Probability * b.score
+		count += b.estimate.Count
+		if b.score > best.score {
+			best = b
+		}
+	}
+
+	return &Estimate[uint32]{
+		Value:       uint32(math.Round(sum / total)),
+		Probability: confidence / total,
+		Count:       count,
+		Provider:    best.estimate.Provider,
+	}
+}
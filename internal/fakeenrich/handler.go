@@ -0,0 +1,280 @@
+// Package fakeenrich emulates the agify, genderize and nationalize APIs for local development
+// and offline tests. Answers are computed from a LocalDataset, names missing from it get the
+// null results the real APIs return for unknown names.
+package fakeenrich
+
+import (
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/models"
+	"encoding/json"
+	"github.com/rs/zerolog/log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxBatchSize is the number of names the real APIs accept in one request.
+	maxBatchSize = 10
+
+	headerRateLimitLimit     = "X-Rate-Limit-Limit"
+	headerRateLimitRemaining = "X-Rate-Limit-Remaining"
+	headerRateLimitReset     = "X-Rate-Limit-Reset"
+)
+
+type Options struct {
+	// Dataset answers the requests. Nil means every name is unknown.
+	Dataset *enrichment.LocalDataset
+
+	// Latency delays every response.
+	Latency time.Duration
+
+	// ErrorRate is the share of requests, from 0 to 1, answered with ErrorStatus.
+	ErrorRate float64
+	// ErrorStatus defaults to 500.
+	ErrorStatus int
+
+	// Limit is the number of names allowed per LimitWindow. Zero means no limit.
+	Limit int
+	// LimitWindow defaults to 24 hours, as on the free tier.
+	LimitWindow time.Duration
+
+	// ApiKey, when set, must be passed in the apikey parameter.
+	ApiKey string
+}
+
+type handler struct {
+	provider string
+	options  Options
+	answer   func(stat models.NameStatistics, name string, countryId string) any
+	now      func() time.Time
+
+	mu      sync.Mutex
+	used    int
+	resetAt time.Time
+}
+
+// NewHandler returns the handler of one API, provider is one of enrichment.ProviderAgify,
+// enrichment.ProviderGenderize or enrichment.ProviderNationalize.
+func NewHandler(provider string, options Options) http.Handler {
+	if options.ErrorStatus == 0 {
+		options.ErrorStatus = http.StatusInternalServerError
+	}
+	if options.LimitWindow == 0 {
+		options.LimitWindow = 24 * time.Hour
+	}
+
+	h := &handler{provider: provider, options: options, now: time.Now}
+	switch provider {
+	case enrichment.ProviderAgify:
+		h.answer = ageAnswer
+	case enrichment.ProviderGenderize:
+		h.answer = genderAnswer
+	case enrichment.ProviderNationalize:
+		h.answer = countryAnswer
+	default:
+		panic("fakeenrich: unknown provider " + provider)
+	}
+	return h
+}
+
+// NewMux serves the three APIs under /agify/, /genderize/ and /nationalize/, each with its own
+// rate limit.
+func NewMux(options Options) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, provider := range []string{enrichment.ProviderAgify, enrichment.ProviderGenderize, enrichment.ProviderNationalize} {
+		mux.Handle("GET /"+provider+"/", NewHandler(provider, options))
+	}
+	return mux
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	log.Debug().
+		Str("provider", h.provider).
+		Str("query", r.URL.RawQuery).
+		Msg("Fake enrichment API request received")
+
+	if h.options.Latency > 0 {
+		select {
+		case <-time.After(h.options.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if h.options.ApiKey != "" && query.Get("apikey") != h.options.ApiKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+
+	names, batch := query["name[]"], true
+	if len(names) == 0 {
+		names, batch = query["name"], false
+	}
+	if len(names) == 0 || (!batch && names[0] == "") {
+		writeError(w, http.StatusUnprocessableEntity, "Missing 'name' parameter")
+		return
+	}
+	if len(names) > maxBatchSize {
+		writeError(w, http.StatusUnprocessableEntity, "Invalid 'name' parameter")
+		return
+	}
+
+	if !h.consume(w, len(names)) {
+		writeError(w, http.StatusTooManyRequests, "Request limit reached")
+		return
+	}
+
+	if h.options.ErrorRate > 0 && rand.Float64() < h.options.ErrorRate {
+		log.Debug().
+			Str("provider", h.provider).
+			Int("status_code", h.options.ErrorStatus).
+			Msg("Injecting fake enrichment API error")
+		writeError(w, h.options.ErrorStatus, http.StatusText(h.options.ErrorStatus))
+		return
+	}
+
+	countryId := query.Get("country_id")
+	answers := make([]any, 0, len(names))
+	for _, name := range names {
+		answers = append(answers, h.answer(h.lookup(name), name, countryId))
+	}
+
+	if batch {
+		writeJson(w, http.StatusOK, answers)
+	} else {
+		writeJson(w, http.StatusOK, answers[0])
+	}
+}
+
+// consume charges count names against the limit and sets the rate limit headers. It reports
+// false when the limit does not allow the request.
+func (h *handler) consume(w http.ResponseWriter, count int) bool {
+	if h.options.Limit == 0 {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	if !now.Before(h.resetAt) {
+		h.used = 0
+		h.resetAt = now.Add(h.options.LimitWindow)
+	}
+
+	allowed := h.used+count <= h.options.Limit
+	if allowed {
+		h.used += count
+	}
+
+	w.Header().Set(headerRateLimitLimit, strconv.Itoa(h.options.Limit))
+	w.Header().Set(headerRateLimitRemaining, strconv.Itoa(h.options.Limit-h.used))
+	w.Header().Set(headerRateLimitReset, strconv.Itoa(int(math.Ceil(h.resetAt.Sub(now).Seconds()))))
+
+	return allowed
+}
+
+func (h *handler) lookup(name string) models.NameStatistics {
+	if h.options.Dataset == nil {
+		return models.NameStatistics{}
+	}
+
+	stat, _ := h.options.Dataset.Statistics(name)
+	return stat
+}
+
+type ageResponse struct {
+	Count     int     `json:"count"`
+	Name      string  `json:"name"`
+	Age       *uint32 `json:"age"`
+	CountryId string  `json:"country_id,omitempty"`
+}
+
+type genderResponse struct {
+	Count       int     `json:"count"`
+	Name        string  `json:"name"`
+	Gender      *string `json:"gender"`
+	Probability float64 `json:"probability"`
+	CountryId   string  `json:"country_id,omitempty"`
+}
+
+type countryResponse struct {
+	Count     int                `json:"count"`
+	Name      string             `json:"name"`
+	Countries []countryCandidate `json:"country"`
+}
+
+type countryCandidate struct {
+	CountryId   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+func ageAnswer(stat models.NameStatistics, name string, countryId string) any {
+	response := ageResponse{Count: stat.AgeCount, Name: name, CountryId: countryId}
+	if stat.AgeCount > 0 {
+		age := uint32(math.Round(stat.MeanAge))
+		response.Age = &age
+	}
+	return response
+}
+
+func genderAnswer(stat models.NameStatistics, name string, countryId string) any {
+	total := stat.MaleCount + stat.FemaleCount
+	response := genderResponse{Count: total, Name: name, CountryId: countryId}
+	if total == 0 {
+		return response
+	}
+
+	gender, count := "male", stat.MaleCount
+	if stat.FemaleCount > stat.MaleCount {
+		gender, count = "female", stat.FemaleCount
+	}
+	response.Gender = &gender
+	response.Probability = roundProbability(float64(count) / float64(total))
+	return response
+}
+
+func countryAnswer(stat models.NameStatistics, name string, _ string) any {
+	total := 0
+	for _, count := range stat.Countries {
+		total += count
+	}
+
+	candidates := make([]countryCandidate, 0, len(stat.Countries))
+	for country, count := range stat.Countries {
+		candidates = append(candidates, countryCandidate{
+			CountryId:   country,
+			Probability: roundProbability(float64(count) / float64(total)),
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Probability != candidates[j].Probability {
+			return candidates[i].Probability > candidates[j].Probability
+		}
+		return candidates[i].CountryId < candidates[j].CountryId
+	})
+
+	return countryResponse{Count: total, Name: name, Countries: candidates}
+}
+
+func roundProbability(probability float64) float64 {
+	return math.Round(probability*100) / 100
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJson(w, statusCode, map[string]string{"error": message})
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error().Err(err).Msg("Failed to write fake enrichment API response")
+	}
+}
@@ -0,0 +1,116 @@
+package fakeenrich
+
+import (
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testDataset() *enrichment.LocalDataset {
+	return enrichment.NewLocalDataset([]models.NameStatistics{
+		{Name: "Anna", AgeCount: 100, MeanAge: 31.6, MaleCount: 5, FemaleCount: 95, Countries: map[string]int{"RU": 60, "PL": 40}},
+	})
+}
+
+func get(t *testing.T, handler http.Handler, query string, target any) *http.Response {
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/?" + query)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if target != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(target))
+	}
+	return resp
+}
+
+func TestHandler(t *testing.T) {
+	options := Options{Dataset: testDataset()}
+
+	t.Run("Agify answers known and unknown names", func(t *testing.T) {
+		var age ageResponse
+		resp := get(t, NewHandler(enrichment.ProviderAgify, options), "name=anna&country_id=PL", &age)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotNil(t, age.Age)
+		assert.Equal(t, uint32(32), *age.Age)
+		assert.Equal(t, 100, age.Count)
+		assert.Equal(t, "PL", age.CountryId)
+
+		var unknown ageResponse
+		get(t, NewHandler(enrichment.ProviderAgify, options), "name=Olga", &unknown)
+		assert.Nil(t, unknown.Age)
+		assert.Equal(t, 0, unknown.Count)
+	})
+
+	t.Run("Genderize answers batch", func(t *testing.T) {
+		var genders []genderResponse
+		get(t, NewHandler(enrichment.ProviderGenderize, options), "name[]=Anna&name[]=Olga", &genders)
+		require.Len(t, genders, 2)
+		require.NotNil(t, genders[0].Gender)
+		assert.Equal(t, "female", *genders[0].Gender)
+		assert.Equal(t, 0.95, genders[0].Probability)
+		assert.Nil(t, genders[1].Gender)
+	})
+
+	t.Run("Nationalize sorts countries", func(t *testing.T) {
+		var country countryResponse
+		get(t, NewHandler(enrichment.ProviderNationalize, options), "name=Anna", &country)
+		require.Len(t, country.Countries, 2)
+		assert.Equal(t, "RU", country.Countries[0].CountryId)
+		assert.Equal(t, 0.6, country.Countries[0].Probability)
+	})
+
+	t.Run("Missing name", func(t *testing.T) {
+		resp := get(t, NewHandler(enrichment.ProviderAgify, options), "", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("Invalid API key", func(t *testing.T) {
+		resp := get(t, NewHandler(enrichment.ProviderAgify, Options{ApiKey: "secret"}), "name=Anna&apikey=wrong", nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Injected error", func(t *testing.T) {
+		resp := get(t, NewHandler(enrichment.ProviderAgify, Options{ErrorRate: 1, ErrorStatus: http.StatusBadGateway}), "name=Anna", nil)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	})
+}
+
+func TestHandlerRateLimit(t *testing.T) {
+	agify := NewHandler(enrichment.ProviderAgify, Options{Limit: 3, LimitWindow: time.Hour})
+	server := httptest.NewServer(agify)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/?name[]=Anna&name[]=Olga")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "3", resp.Header.Get(headerRateLimitLimit))
+	assert.Equal(t, "1", resp.Header.Get(headerRateLimitRemaining))
+	assert.Equal(t, "3600", resp.Header.Get(headerRateLimitReset))
+
+	resp, err = http.Get(server.URL + "/?name[]=Anna&name[]=Olga")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(headerRateLimitRemaining))
+
+	limited := agify.(*handler)
+	limited.mu.Lock()
+	limited.now = func() time.Time { return time.Now().Add(time.Hour) }
+	limited.mu.Unlock()
+
+	resp, err = http.Get(server.URL + "/?name=Anna")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get(headerRateLimitRemaining))
+}
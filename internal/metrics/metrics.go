@@ -0,0 +1,143 @@
+// Package metrics holds the Prometheus collectors of the application. They are registered
+// on Registry rather than the global default registry, so tests can read them in isolation.
+package metrics
+
+import (
+	"context"
+	"effective-mobile/internal/models/custom_errors"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "effective_mobile"
+
+// Outcomes used as the outcome label of the latency histograms.
+const (
+	OutcomeSuccess        = "success"
+	OutcomeError          = "error"
+	OutcomeQuotaExhausted = "quota_exhausted"
+	OutcomeTimeout        = "timeout"
+	OutcomeCanceled       = "canceled"
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of PersonDriver operations by operation and outcome.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "outcome"})
+
+	providerRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "enrichment_provider_request_duration_seconds",
+		Help:      "Latency of upstream enrichment API calls by provider and outcome.",
+		Buckets:   []float64{.025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"provider", "outcome"})
+
+	providerErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichment_provider_errors_total",
+		Help:      "Failed upstream enrichment API calls by provider and outcome.",
+	}, []string{"provider", "outcome"})
+
+	providerCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichment_provider_calls_total",
+		Help:      "Enrichment API lookups by provider. Coalesced lookups shared an in-flight call instead of making their own.",
+	}, []string{"provider", "coalesced"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests rejected with 429 by rate limit policy.",
+	}, []string{"policy"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		queryDuration,
+		providerRequestDuration,
+		providerErrors,
+		providerCalls,
+		rateLimited,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHttpRequest records a served request. Route must be the route pattern, not the raw
+// path, to keep the number of series bounded.
+func ObserveHttpRequest(method string, route string, status int, duration time.Duration) {
+	statusCode := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, statusCode).Inc()
+	httpRequestDuration.WithLabelValues(method, route, statusCode).Observe(duration.Seconds())
+}
+
+// ObserveQuery records the latency of a database operation started at startedAt.
+func ObserveQuery(operation string, startedAt time.Time, err error) {
+	queryDuration.WithLabelValues(operation, Outcome(err)).Observe(time.Since(startedAt).Seconds())
+}
+
+// ObserveProviderRequest records an upstream enrichment API call and counts it as an error
+// when it failed.
+func ObserveProviderRequest(provider string, duration time.Duration, err error) {
+	outcome := Outcome(err)
+	providerRequestDuration.WithLabelValues(provider, outcome).Observe(duration.Seconds())
+	if err != nil {
+		providerErrors.WithLabelValues(provider, outcome).Inc()
+	}
+}
+
+// CountProviderCall counts a lookup of provider, coalesced when it reused an in-flight call.
+func CountProviderCall(provider string, coalesced bool) {
+	providerCalls.WithLabelValues(provider, strconv.FormatBool(coalesced)).Inc()
+}
+
+// CountRateLimited counts a request rejected by the rate limit policy.
+func CountRateLimited(policy string) {
+	rateLimited.WithLabelValues(policy).Inc()
+}
+
+// Outcome maps an error to the outcome label.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, custom_errors.ErrQuotaExhausted):
+		return OutcomeQuotaExhausted
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return OutcomeCanceled
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"effective-mobile/internal/models/custom_errors"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, Outcome(nil))
+	assert.Equal(t, OutcomeQuotaExhausted, Outcome(fmt.Errorf("agify: %w", custom_errors.ErrQuotaExhausted)))
+	assert.Equal(t, OutcomeTimeout, Outcome(context.DeadlineExceeded))
+	assert.Equal(t, OutcomeCanceled, Outcome(context.Canceled))
+	assert.Equal(t, OutcomeError, Outcome(errors.New("boom")))
+}
+
+func TestObserve(t *testing.T) {
+	t.Run("HTTP requests by route and status", func(t *testing.T) {
+		before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/persons/:id", "404"))
+		ObserveHttpRequest("GET", "/persons/:id", http.StatusNotFound, 10*time.Millisecond)
+		assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/persons/:id", "404")))
+	})
+
+	t.Run("Provider errors and coalesced calls", func(t *testing.T) {
+		errorsBefore := testutil.ToFloat64(providerErrors.WithLabelValues("agify", OutcomeQuotaExhausted))
+		ObserveProviderRequest("agify", time.Millisecond, custom_errors.ErrQuotaExhausted)
+		ObserveProviderRequest("agify", time.Millisecond, nil)
+		assert.Equal(t, errorsBefore+1, testutil.ToFloat64(providerErrors.WithLabelValues("agify", OutcomeQuotaExhausted)))
+
+		sharedBefore := testutil.ToFloat64(providerCalls.WithLabelValues("agify", "true"))
+		CountProviderCall("agify", true)
+		CountProviderCall("agify", false)
+		assert.Equal(t, sharedBefore+1, testutil.ToFloat64(providerCalls.WithLabelValues("agify", "true")))
+	})
+
+	t.Run("Handler exposes the metrics", func(t *testing.T) {
+		ObserveQuery("get_persons", time.Now(), nil)
+
+		recorder := httptest.NewRecorder()
+		Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		body := recorder.Body.String()
+		assert.Contains(t, body, `effective_mobile_db_query_duration_seconds_count{operation="get_persons",outcome="success"}`)
+		assert.Contains(t, body, "go_goroutines")
+	})
+}
@@ -0,0 +1,36 @@
+package middlerwares
+
+import (
+	"effective-mobile/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// AuditMiddleware records who changed data. It logs every request that is not a read once the
+// handler has finished, with the principal added to the log by AuthMiddleware.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+
+		ctx := c.Request.Context()
+		subject := "anonymous"
+		if principal := auth.FromContext(ctx); principal != nil {
+			subject = principal.Subject
+		}
+
+		log.Ctx(ctx).Info().
+			Bool("audit", true).
+			Str("actor", subject).
+			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
+			Str("path", c.Request.URL.Path).
+			Int("status", c.Writer.Status()).
+			Msg("Audit")
+	}
+}
@@ -0,0 +1,71 @@
+package middlerwares
+
+import (
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
+	"effective-mobile/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strings"
+)
+
+const (
+	ApiKeyHeader        = "X-API-Key"
+	AuthorizationHeader = "Authorization"
+
+	bearerScheme = "Bearer"
+)
+
+// AuthMiddleware authenticates the request with the X-API-Key header or an
+// "Authorization: Bearer" token and stores the principal in the gin and request contexts.
+// Requests without valid credentials are rejected with 401.
+func AuthMiddleware(authService services.AuthServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var principal *models.Principal
+		var err error
+
+		if key := c.GetHeader(ApiKeyHeader); key != "" {
+			principal, err = authService.AuthenticateApiKey(ctx, key)
+		} else if token, ok := bearerToken(c.GetHeader(AuthorizationHeader)); ok {
+			principal, err = authService.AuthenticateToken(ctx, token)
+		} else {
+			err = custom_errors.ErrMissingCredentials
+		}
+
+		if err != nil {
+			unauthorized := problem.New(c, err)
+			if unauthorized.Status == http.StatusUnauthorized {
+				c.Header("WWW-Authenticate", bearerScheme)
+			}
+
+			log.Ctx(ctx).Warn().
+				Err(err).
+				Int("status", unauthorized.Status).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Str("client_ip", c.ClientIP()).
+				Msg("Authentication failed")
+			problem.Write(c, unauthorized)
+			return
+		}
+
+		c.Set("Principal", principal)
+		c.Request = c.Request.WithContext(auth.NewContext(ctx, principal))
+
+		c.Next()
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, bearerScheme) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,127 @@
+package middlerwares
+
+import (
+	"context"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) AuthenticateApiKey(ctx context.Context, key string) (*models.Principal, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Principal), args.Error(1)
+}
+
+func (m *MockAuthService) AuthenticateToken(ctx context.Context, token string) (*models.Principal, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Principal), args.Error(1)
+}
+
+func (m *MockAuthService) CreateApiKey(ctx context.Context, name string, roles []string) (string, *models.ApiKey, error) {
+	args := m.Called(ctx, name, roles)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*models.ApiKey), args.Error(2)
+}
+
+func (m *MockAuthService) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ApiKey), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeApiKey(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAuthService)
+
+	router := gin.New()
+	router.Use(AuthMiddleware(mockService))
+	router.GET("/persons", func(c *gin.Context) {
+		principal := auth.FromContext(c.Request.Context())
+		c.String(http.StatusOK, principal.Method+":"+principal.Subject)
+	})
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/persons", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("API key", func(t *testing.T) {
+		mockService.On("AuthenticateApiKey", mock.Anything, "em_key").
+			Return(&models.Principal{Subject: "billing", Method: models.AuthMethodApiKey}, nil).Once()
+
+		w := serve(ApiKeyHeader, "em_key")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "api_key:billing", w.Body.String())
+	})
+
+	t.Run("Bearer token", func(t *testing.T) {
+		mockService.On("AuthenticateToken", mock.Anything, "token").
+			Return(&models.Principal{Subject: "service-a", Method: models.AuthMethodJwt}, nil).Once()
+
+		w := serve(AuthorizationHeader, "bearer token")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "jwt:service-a", w.Body.String())
+	})
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		w := serve(AuthorizationHeader, "Basic dXNlcjpwYXNz")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Invalid API key", func(t *testing.T) {
+		mockService.On("AuthenticateApiKey", mock.Anything, "em_revoked").
+			Return(nil, custom_errors.ErrInvalidApiKey).Once()
+
+		w := serve(ApiKeyHeader, "em_revoked")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_api_key"`)
+	})
+
+	t.Run("Database failure", func(t *testing.T) {
+		mockService.On("AuthenticateApiKey", mock.Anything, "em_key").
+			Return(nil, custom_errors.ErrGetApiKey).Once()
+
+		w := serve(ApiKeyHeader, "em_key")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+		assert.NotContains(t, w.Body.String(), custom_errors.ErrGetApiKey.Message)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package middlerwares
+
+import (
+	"bytes"
+	"context"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
+	"effective-mobile/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func IdempotencyMiddleware(idempotencyService services.IdempotencyServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		requestId, _ := c.Get("RequestID")
+		reqIdStr, _ := requestId.(string)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("request_id", reqIdStr).
+				Msg(custom_errors.ErrBindJsonBody.Message)
+			problem.AbortBind(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := services.HashRequest(c.Request.Method, c.FullPath(), body)
+
+		// Keys are scoped to the caller. Without authentication every request shares one scope.
+		principal := ""
+		if p := auth.FromContext(c.Request.Context()); p != nil {
+			principal = p.Id()
+		}
+
+		record, err := idempotencyService.Begin(c.Request.Context(), principal, key, requestHash)
+		if err != nil {
+			rejected := problem.New(c, err)
+
+			log.Warn().
+				Err(err).
+				Str("request_id", reqIdStr).
+				Int("status", rejected.Status).
+				Msg("Idempotency check failed")
+			problem.Write(c, rejected)
+			return
+		}
+
+		if record != nil {
+			log.Info().
+				Str("request_id", reqIdStr).
+				Int("status", record.StatusCode).
+				Msg("Replaying idempotent response")
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// Deferred so a handler that panics, answered by the recovery middleware, still releases
+		// the key, otherwise every retry gets 409 until the key expires.
+		finished := false
+		defer func() {
+			// The request context may already be cancelled by the time the handler returns,
+			// but the outcome still has to be recorded, otherwise the key stays locked until expiry.
+			ctx := context.WithoutCancel(c.Request.Context())
+			status := recorder.Status()
+
+			if !finished || !recorder.Written() || status >= http.StatusInternalServerError {
+				if err := idempotencyService.Release(ctx, principal, key); err != nil {
+					log.Error().
+						Err(err).
+						Str("request_id", reqIdStr).
+						Bool("handler_finished", finished).
+						Msg("Failed to release idempotency key after server error")
+				}
+				return
+			}
+
+			if err := idempotencyService.Complete(ctx, principal, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+				log.Error().
+					Err(err).
+					Str("request_id", reqIdStr).
+					Msg("Failed to store idempotent response")
+			}
+		}()
+
+		c.Next()
+		finished = true
+	}
+}
@@ -0,0 +1,123 @@
+package middlerwares
+
+import (
+	"bytes"
+	"context"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockIdempotencyService struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyService) Begin(ctx context.Context, principal, key, requestHash string) (*models.IdempotencyRecord, error) {
+	args := m.Called(ctx, principal, key, requestHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, principal, key string, statusCode int, contentType string, body []byte) error {
+	args := m.Called(ctx, principal, key, statusCode, contentType, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, principal, key string) error {
+	args := m.Called(ctx, principal, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) PurgeExpired(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serveAs := func(principal *models.Principal, service *MockIdempotencyService, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}))
+		if principal != nil {
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+			})
+		}
+		router.POST("/persons", IdempotencyMiddleware(service), handler)
+
+		req, _ := http.NewRequest("POST", "/persons", bytes.NewBufferString(`{"name": "Anna"}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	serve := func(service *MockIdempotencyService, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		return serveAs(nil, service, handler)
+	}
+
+	t.Run("Response is stored", func(t *testing.T) {
+		service := new(MockIdempotencyService)
+		service.On("Begin", mock.Anything, "", "key-1", mock.Anything).Return(nil, nil).Once()
+		service.On("Complete", mock.Anything, "", "key-1", http.StatusCreated, mock.Anything, []byte(`{"id":"1"}`)).Return(nil).Once()
+
+		w := serve(service, func(c *gin.Context) {
+			c.Data(http.StatusCreated, "application/json", []byte(`{"id":"1"}`))
+		})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Server error releases the key", func(t *testing.T) {
+		service := new(MockIdempotencyService)
+		service.On("Begin", mock.Anything, "", "key-1", mock.Anything).Return(nil, nil).Once()
+		service.On("Release", mock.Anything, "", "key-1").Return(nil).Once()
+
+		w := serve(service, func(c *gin.Context) {
+			c.Status(http.StatusInternalServerError)
+			c.Writer.WriteHeaderNow()
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		service.AssertExpectations(t)
+		service.AssertNotCalled(t, "Complete")
+	})
+
+	t.Run("Panicking handler releases the key", func(t *testing.T) {
+		service := new(MockIdempotencyService)
+		service.On("Begin", mock.Anything, "", "key-1", mock.Anything).Return(nil, nil).Once()
+		service.On("Release", mock.Anything, "", "key-1").Return(nil).Once()
+
+		w := serve(service, func(c *gin.Context) {
+			panic("handler failed")
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		service.AssertExpectations(t)
+		service.AssertNotCalled(t, "Complete")
+	})
+
+	t.Run("Key is scoped to the principal", func(t *testing.T) {
+		service := new(MockIdempotencyService)
+		service.On("Begin", mock.Anything, "api_key:ci", "key-1", mock.Anything).Return(nil, nil).Once()
+		service.On("Complete", mock.Anything, "api_key:ci", "key-1", http.StatusCreated, mock.Anything, mock.Anything).Return(nil).Once()
+
+		principal := &models.Principal{Subject: "ci", Method: models.AuthMethodApiKey}
+		w := serveAs(principal, service, func(c *gin.Context) {
+			c.Data(http.StatusCreated, "application/json", []byte(`{"id":"1"}`))
+		})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		service.AssertExpectations(t)
+	})
+}
@@ -3,6 +3,7 @@ package middlerwares
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 	"time"
 )
 
@@ -24,7 +25,12 @@ func LoggingMiddleware() gin.HandlerFunc {
 		clientIP := c.ClientIP()
 		method := c.Request.Method
 
-		log.Info().
+		event := log.Info()
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+			event = event.Str("trace_id", spanContext.TraceID().String())
+		}
+
+		event.
 			Str("request_id", reqIdStr).
 			Int("status", statusCode).
 			Str("method", method).
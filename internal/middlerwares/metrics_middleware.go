@@ -0,0 +1,25 @@
+package middlerwares
+
+import (
+	"effective-mobile/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanning for random paths does
+// not create a series per path.
+const unmatchedRoute = "unmatched"
+
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHttpRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
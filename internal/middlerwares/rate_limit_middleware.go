@@ -0,0 +1,62 @@
+package middlerwares
+
+import (
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
+	"effective-mobile/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimitMiddleware counts the request against the policy bucket of the caller: the API key
+// or token subject when the request is authenticated, the client IP otherwise. Requests over
+// the limit are rejected with 429. When the limit storage fails the request is let through,
+// an outage of the shared storage must not take the API down.
+func RateLimitMiddleware(rateLimitService services.RateLimitServiceInterface, policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		client := "ip:" + c.ClientIP()
+		if principal := auth.FromContext(ctx); principal != nil {
+			client = principal.Id()
+		}
+
+		result, err := rateLimitService.Allow(ctx, policy, client)
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("policy", policy).
+				Msg("Rate limit check failed, letting the request through")
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(seconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header(RetryAfterHeader, strconv.Itoa(max(seconds(result.RetryAfter), 1)))
+			problem.Abort(c, custom_errors.ErrRateLimited)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds up, so a client waiting for the advertised time is not rejected again.
+func seconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,100 @@
+package middlerwares
+
+import (
+	"context"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockRateLimitService struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitService) Allow(ctx context.Context, policy, client string) (*models.RateLimitResult, error) {
+	args := m.Called(ctx, policy, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RateLimitResult), args.Error(1)
+}
+
+func (m *MockRateLimitService) PurgeIdle(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(service *MockRateLimitService, principal *models.Principal) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if principal != nil {
+				c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+			}
+		})
+		router.POST("/persons", RateLimitMiddleware(service, models.RateLimitPolicyCreate), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+
+		req, _ := http.NewRequest("POST", "/persons", nil)
+		req.RemoteAddr = "10.0.0.1:41000"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Allowed request by API key", func(t *testing.T) {
+		service := new(MockRateLimitService)
+		service.On("Allow", mock.Anything, models.RateLimitPolicyCreate, "api_key:billing").Return(&models.RateLimitResult{
+			Allowed:   true,
+			Limit:     10,
+			Remaining: 9,
+			Reset:     1500 * time.Millisecond,
+		}, nil).Once()
+
+		w := serve(service, &models.Principal{Subject: "billing", Method: models.AuthMethodApiKey})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "10", w.Header().Get(RateLimitLimitHeader))
+		assert.Equal(t, "9", w.Header().Get(RateLimitRemainingHeader))
+		assert.Equal(t, "2", w.Header().Get(RateLimitResetHeader))
+		assert.Empty(t, w.Header().Get(RetryAfterHeader))
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Rejected request by client IP", func(t *testing.T) {
+		service := new(MockRateLimitService)
+		service.On("Allow", mock.Anything, models.RateLimitPolicyCreate, "ip:10.0.0.1").Return(&models.RateLimitResult{
+			Limit:      10,
+			Reset:      20 * time.Second,
+			RetryAfter: 100 * time.Millisecond,
+		}, nil).Once()
+
+		w := serve(service, nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get(RateLimitRemainingHeader))
+		assert.Equal(t, "1", w.Header().Get(RetryAfterHeader))
+		assert.Contains(t, w.Body.String(), `"code":"rate_limited"`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("Storage failure lets the request through", func(t *testing.T) {
+		service := new(MockRateLimitService)
+		service.On("Allow", mock.Anything, mock.Anything, mock.Anything).Return(nil, custom_errors.ErrTakeRateLimitToken).Once()
+
+		w := serve(service, nil)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	})
+}
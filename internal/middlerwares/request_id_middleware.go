@@ -1,16 +1,31 @@
 package middlerwares
 
 import (
+	"effective-mobile/internal/requestid"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
+// RequestIdMiddleware keeps the X-Request-ID sent by the gateway when it is well-formed and
+// generates one otherwise. The id is stored in the request context for the service, driver and
+// enrichment logs and is echoed in the response.
 func RequestIdMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestId := uuid.New().String()
+		requestId := c.GetHeader(requestid.Header)
+		if requestId != "" && !requestid.Valid(requestId) {
+			log.Warn().
+				Int("length", len(requestId)).
+				Str("path", c.Request.URL.Path).
+				Msg("Ignoring malformed inbound request id")
+			requestId = ""
+		}
+		if requestId == "" {
+			requestId = requestid.New()
+		}
+
 		c.Set("RequestID", requestId)
-		c.Header("X-Request-ID", requestId)
+		c.Header(requestid.Header, requestId)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestId))
 
 		log.Debug().
 			Str("request_id", requestId).
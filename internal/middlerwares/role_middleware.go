@@ -0,0 +1,43 @@
+package middlerwares
+
+import (
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// RequireRole lets the request through only when the principal set by AuthMiddleware has the
+// role or a more privileged one, otherwise it answers 403 with the required role
+// and the roles of the principal.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		principal := auth.FromContext(ctx)
+		if principal == nil {
+			log.Ctx(ctx).Error().
+				Str("path", c.Request.URL.Path).
+				Msg("Role check without an authenticated principal")
+			problem.Abort(c, custom_errors.ErrMissingCredentials)
+			return
+		}
+
+		if !principal.HasRole(role) {
+			log.Ctx(ctx).Warn().
+				Str("required_role", role).
+				Str("method", c.Request.Method).
+				Str("route", c.FullPath()).
+				Msg(custom_errors.ErrForbidden.Message)
+
+			forbidden := problem.New(c, custom_errors.ErrForbidden)
+			forbidden.RequiredRole = role
+			forbidden.Roles = principal.Roles
+			problem.Write(c, forbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
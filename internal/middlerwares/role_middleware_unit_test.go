@@ -0,0 +1,84 @@
+package middlerwares
+
+import (
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(principal *models.Principal) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if principal != nil {
+				c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+			}
+		})
+		router.DELETE("/persons/:id", RequireRole(models.RoleAdmin), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest("DELETE", "/persons/1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Admin can delete", func(t *testing.T) {
+		w := serve(&models.Principal{Subject: "ops", Roles: []string{models.RoleAdmin}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Editor cannot delete", func(t *testing.T) {
+		w := serve(&models.Principal{Subject: "billing", Roles: []string{models.RoleReader, models.RoleEditor}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var response dtos.ProblemDto
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Equal(t, "forbidden", response.Code)
+		assert.Equal(t, models.RoleAdmin, response.RequiredRole)
+		assert.Equal(t, []string{models.RoleReader, models.RoleEditor}, response.Roles)
+	})
+
+	t.Run("Principal without roles", func(t *testing.T) {
+		w := serve(&models.Principal{Subject: "service-a"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{
+			"type": "urn:effective-mobile:problem:forbidden",
+			"title": "Forbidden",
+			"status": 403,
+			"detail": "principal has no role allowed to perform this operation",
+			"instance": "/persons/1",
+			"code": "forbidden",
+			"request_id": "",
+			"required_role": "admin"
+		}`, w.Body.String())
+	})
+
+	t.Run("Unauthenticated request", func(t *testing.T) {
+		w := serve(nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestPrincipalHasRole(t *testing.T) {
+	editor := &models.Principal{Roles: []string{models.RoleEditor}}
+
+	assert.True(t, editor.HasRole(models.RoleReader))
+	assert.True(t, editor.HasRole(models.RoleEditor))
+	assert.False(t, editor.HasRole(models.RoleAdmin))
+	assert.False(t, (&models.Principal{Roles: []string{"owner"}}).HasRole(models.RoleReader))
+}
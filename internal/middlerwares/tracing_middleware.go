@@ -0,0 +1,19 @@
+package middlerwares
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"net/http"
+	"slices"
+)
+
+// untracedPaths are polled by orchestrators and Prometheus, tracing them only adds noise.
+var untracedPaths = []string{"/livez", "/readyz", "/health", "/metrics"}
+
+// TracingMiddleware starts a server span for every request, continuing the trace from the
+// traceparent header when the caller sent one.
+func TracingMiddleware(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !slices.Contains(untracedPaths, r.URL.Path)
+	}))
+}
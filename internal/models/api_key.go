@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ApiKey is a static key of a client. Only the SHA-256 hash of the key is stored, the prefix
+// lets operators recognise a key without revealing it.
+type ApiKey struct {
+	Id        int64
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Roles     []string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+func (k *ApiKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,13 @@
+package custom_errors
+
+import "strings"
+
+// ConfigError lists every problem found while loading the configuration, so all of them can
+// be fixed at once.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
@@ -0,0 +1,31 @@
+package custom_errors
+
+import "strings"
+
+// ProviderFailure describes why the provider of one field failed.
+type ProviderFailure struct {
+	Field    string
+	Provider string
+	Err      error
+}
+
+// EnrichmentError aggregates the provider failures of a single enrichment.
+type EnrichmentError struct {
+	Failures []ProviderFailure
+}
+
+func (e *EnrichmentError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		parts = append(parts, failure.Field+" ("+failure.Provider+"): "+failure.Err.Error())
+	}
+	return "failed to enrich person: " + strings.Join(parts, "; ")
+}
+
+func (e *EnrichmentError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
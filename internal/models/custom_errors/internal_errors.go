@@ -18,23 +18,59 @@ var (
 	ErrStartServer    = &InternalError{Message: "failed to start server"}
 	ErrShutdownServer = &InternalError{Message: "failed to shutdown server"}
 	ErrEnvLoading     = &InternalError{Message: "failed to load .env file loading"}
+	ErrConfigLoading  = &InternalError{Message: "failed to load config file"}
+	ErrInitTracing    = &InternalError{Message: "failed to initialize tracing"}
 
 	ErrBindJsonBody = &InternalError{Message: "failed to bind json body"}
 
-	ErrCreatePool = &InternalError{Message: "failed to create connection pool"}
-	ErrScanRow    = &InternalError{Message: "failed to scan row"}
+	ErrCreatePool      = &InternalError{Message: "failed to create connection pool"}
+	ErrParsePoolConfig = &InternalError{Message: "failed to parse database connection string"}
+	ErrConnectDatabase = &InternalError{Message: "database is unreachable, giving up"}
+	ErrScanRow         = &InternalError{Message: "failed to scan row"}
+	ErrPingDatabase    = &InternalError{Message: "database did not answer the ping"}
+
+	ErrInitMigrations        = &InternalError{Message: "failed to initialize migrations"}
+	ErrApplyMigrations       = &InternalError{Message: "failed to apply migrations"}
+	ErrGetMigrationStatus    = &InternalError{Message: "failed to get migration status"}
+	ErrNoMigrationToRollBack = &InternalError{Message: "no applied migration to roll back"}
 
 	ErrCreatePerson  = &InternalError{Message: "failed to create person"}
 	ErrUpdatePerson  = &InternalError{Message: "failed to update person"}
 	ErrGetPersonById = &InternalError{Message: "failed to get person by id"}
 	ErrGetPerson     = &InternalError{Message: "failed to get person"}
+	ErrCountPersons  = &InternalError{Message: "failed to count persons"}
 	ErrDeletePerson  = &InternalError{Message: "failed to delete person"}
 
-	ErrHttpGet = &InternalError{Message: "failed to http get"}
+	ErrApplyEnrichment = &InternalError{Message: "failed to apply enrichment to person"}
+
+	ErrGetEnrichmentResults = &InternalError{Message: "failed to get enrichment results"}
+	ErrGetNameStatistics    = &InternalError{Message: "failed to get name statistics"}
+
+	ErrReserveIdempotencyKey  = &InternalError{Message: "failed to reserve idempotency key"}
+	ErrGetIdempotencyKey      = &InternalError{Message: "failed to get idempotency key"}
+	ErrCompleteIdempotencyKey = &InternalError{Message: "failed to complete idempotency key"}
+	ErrDeleteIdempotencyKey   = &InternalError{Message: "failed to delete idempotency key"}
+
+	ErrCreateApiKey = &InternalError{Message: "failed to create api key"}
+	ErrGetApiKey    = &InternalError{Message: "failed to get api key"}
+	ErrListApiKeys  = &InternalError{Message: "failed to list api keys"}
+	ErrRevokeApiKey = &InternalError{Message: "failed to revoke api key"}
+	ErrLoadJwks     = &InternalError{Message: "failed to load jwks file"}
+
+	ErrTakeRateLimitToken     = &InternalError{Message: "failed to take rate limit token"}
+	ErrDeleteRateLimitBuckets = &InternalError{Message: "failed to delete idle rate limit buckets"}
+	ErrUnknownRateLimitPolicy = &InternalError{Message: "unknown rate limit policy"}
+
+	ErrHttpGet        = &InternalError{Message: "failed to http get"}
+	ErrQuotaExhausted = &InternalError{Message: "enrichment provider quota exhausted"}
+
+	ErrEnrichmentTimeout = &InternalError{Message: "enrichment deadline exceeded"}
+	ErrEnrichmentCancel  = &InternalError{Message: "enrichment cancelled"}
 
 	ErrGetAgeStatusCode    = &InternalError{Message: "failed to get age. status code is not 200"}
 	ErrGetAgeReadBody      = &InternalError{Message: "failed to read body while getting age"}
 	ErrGetAgeUnmarshalBody = &InternalError{Message: "failed to unmarshal body while getting age"}
+	ErrGotNoAge            = &InternalError{Message: "failed to get age. no age estimate"}
 
 	ErrGetGenderStatusCode    = &InternalError{Message: "failed to get gender. status code is not 200"}
 	ErrGetGenderReadBody      = &InternalError{Message: "failed to read body while getting gender"}
@@ -44,4 +80,20 @@ var (
 	ErrGetCountryStatusCode    = &InternalError{Message: "failed to get country. status code is not 200"}
 	ErrGetCountryReadBody      = &InternalError{Message: "failed to read body while getting country"}
 	ErrGetCountryUnmarshalBody = &InternalError{Message: "failed to unmarshal body while getting country"}
+	ErrGotNoCountry            = &InternalError{Message: "failed to get country. no country candidates"}
+
+	ErrLoadDataset      = &InternalError{Message: "failed to load name dataset"}
+	ErrParseDataset     = &InternalError{Message: "failed to parse name dataset"}
+	ErrWriteDataset     = &InternalError{Message: "failed to write name dataset"}
+	ErrNameNotInDataset = &InternalError{Message: "name not found in local dataset"}
+	ErrInvalidLocalMode = &InternalError{Message: "invalid local enrichment mode"}
+
+	ErrGenderNotInferred      = &InternalError{Message: "failed to infer gender from patronymic or surname"}
+	ErrInvalidGenderRulesMode = &InternalError{Message: "invalid gender rules mode"}
+
+	ErrNoConfidentEstimate   = &InternalError{Message: "no provider returned an estimate above the confidence threshold"}
+	ErrUnknownProvider       = &InternalError{Message: "unknown enrichment provider"}
+	ErrInvalidStrategy       = &InternalError{Message: "invalid enrichment strategy"}
+	ErrParseWeights          = &InternalError{Message: "failed to parse enrichment provider weights"}
+	ErrInvalidDefaultCountry = &InternalError{Message: "invalid default enrichment country"}
 )
@@ -16,12 +16,37 @@ func (e *UserError) Error() string {
 
 var (
 	ErrPersonNotFound   = &UserError{Message: "person not found"}
-	ErrNoFieldsToUpdate = &InternalError{Message: "no fields to update"}
-
-	ErrInvalidUuid   = &InternalError{Message: "invalid UUID"}
-	ErrLimitValue    = &UserError{Message: "limit cannot be negative"}
-	ErrOffsetValue   = &UserError{Message: "offset cannot be negative"}
-	ErrLowAgeValue   = &UserError{Message: "low age cannot be negative"}
-	ErrHighAgeValue  = &UserError{Message: "high age cannot be negative"}
-	ErrInvalidGender = &UserError{Message: "gender must be either 'male' or 'female'"}
+	ErrEmptyName        = &UserError{Message: "name cannot be empty"}
+	ErrNoFieldsToUpdate = &UserError{Message: "no fields to update"}
+
+	ErrInvalidPersonId    = &UserError{Message: "id must be a UUID"}
+	ErrInvalidUuid        = &UserError{Message: "ids must contain only UUIDs"}
+	ErrLimitValue         = &UserError{Message: "limit cannot be negative"}
+	ErrOffsetValue        = &UserError{Message: "offset cannot be negative"}
+	ErrLowAgeValue        = &UserError{Message: "low age cannot be negative"}
+	ErrHighAgeValue       = &UserError{Message: "high age cannot be negative"}
+	ErrInvalidGender      = &UserError{Message: "gender must be either 'male' or 'female'"}
+	ErrAgeValue           = &UserError{Message: "age must be between 0 and 150"}
+	ErrInvalidCountry     = &UserError{Message: "country must be an ISO 3166-1 alpha-2 code, for example 'RU'"}
+	ErrInvalidCountryHint = &UserError{Message: "country_hint must be an ISO 3166-1 alpha-2 code, for example 'RU'"}
+	ErrInvalidSource      = &UserError{Message: "source must be one of 'manual', 'import', 'enriched' or 'enriched:<provider>'"}
+
+	ErrInvalidIdempotencyKey        = &UserError{Message: "idempotency key must be from 1 to 255 characters long"}
+	ErrIdempotencyKeyReused         = &UserError{Message: "idempotency key has already been used with a different request"}
+	ErrIdempotencyRequestInProgress = &UserError{Message: "request with this idempotency key is still being processed"}
+
+	ErrMissingCredentials = &UserError{Message: "authentication required: send an X-API-Key header or a bearer token"}
+	ErrInvalidApiKey      = &UserError{Message: "api key is invalid or revoked"}
+	ErrInvalidToken       = &UserError{Message: "bearer token is invalid or expired"}
+	ErrEmptyApiKeyName    = &UserError{Message: "api key name cannot be empty"}
+	ErrApiKeyNameTaken    = &UserError{Message: "api key with this name already exists"}
+	ErrApiKeyNotFound     = &UserError{Message: "active api key with this name not found"}
+	ErrInvalidRole        = &UserError{Message: "roles must be one or more of 'reader', 'editor' or 'admin'"}
+	ErrForbidden          = &UserError{Message: "principal has no role allowed to perform this operation"}
+
+	ErrRateLimited = &UserError{Message: "rate limit exceeded, retry later"}
+
+	ErrMalformedBody    = &UserError{Message: "request body is not valid JSON"}
+	ErrRouteNotFound    = &UserError{Message: "no route matches the request path"}
+	ErrMethodNotAllowed = &UserError{Message: "method is not allowed for the request path"}
 )
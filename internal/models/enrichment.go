@@ -0,0 +1,70 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type EnrichmentRequest struct {
+	Name       string
+	Surname    string
+	Patronymic string
+
+	// CountryId is an ISO 3166-1 alpha-2 hint passed to the providers that support localization.
+	CountryId string
+
+	// Fields lists the attributes to enrich. An empty list means all of them.
+	Fields []string
+}
+
+func (r EnrichmentRequest) Wants(field string) bool {
+	return len(r.Fields) == 0 || slices.Contains(r.Fields, field)
+}
+
+type Enrichment struct {
+	Age     uint32
+	Gender  GenderType
+	Country string
+
+	AgeProvider     string
+	GenderProvider  string
+	CountryProvider string
+
+	AgeCount           int
+	GenderProbability  float64
+	GenderCount        int
+	CountryProbability float64
+	CountryCount       int
+	CountryCandidates  []CountryCandidate
+
+	// Results lists every provider answer considered for the returned values.
+	Results []ProviderResult
+}
+
+type CountryCandidate struct {
+	Country     string
+	Probability float64
+}
+
+// ProviderResult is one provider answer for a field, kept for auditing.
+type ProviderResult struct {
+	Field      string
+	Provider   string
+	Value      string
+	Confidence float64
+	Error      string
+	Chosen     bool
+	CreatedAt  time.Time
+}
+
+type EnrichmentChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+const (
+	FieldAge     = "age"
+	FieldGender  = "gender"
+	FieldCountry = "country"
+)
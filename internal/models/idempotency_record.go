@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type IdempotencyRecord struct {
+	// Principal is the caller the key belongs to, empty when authentication is disabled.
+	Principal    string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// IsCompleted reports whether the response for the key has already been stored.
+// A record without a status code is still being processed by another request.
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// MigrationStatus describes one embedded schema migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import "strings"
+
+// NameStatistics aggregates what is known about the people sharing a first name.
+type NameStatistics struct {
+	Name        string
+	AgeCount    int
+	MeanAge     float64
+	MaleCount   int
+	FemaleCount int
+	Countries   map[string]int
+}
+
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
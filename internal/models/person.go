@@ -1,6 +1,10 @@
 package models
 
-import "github.com/jackc/pgx/v5/pgtype"
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
+	"time"
+)
 
 type Person struct {
 	Id         pgtype.UUID
@@ -10,6 +14,52 @@ type Person struct {
 	Age        uint32
 	Gender     GenderType
 	Country    string
+
+	AgeSource     AttributeSource
+	GenderSource  AttributeSource
+	CountrySource AttributeSource
+}
+
+type AttributeSource struct {
+	Source    string
+	UpdatedAt time.Time
+}
+
+const (
+	SourceManual         = "manual"
+	SourceImport         = "import"
+	SourceEnriched       = "enriched"
+	sourceEnrichedPrefix = SourceEnriched + ":"
+)
+
+func EnrichedSource(provider string) string {
+	return sourceEnrichedPrefix + provider
+}
+
+func (s AttributeSource) IsEnriched() bool {
+	return strings.HasPrefix(s.Source, sourceEnrichedPrefix)
+}
+
+func IsValidSource(source string) bool {
+	switch source {
+	case SourceManual, SourceImport, SourceEnriched:
+		return true
+	}
+	return strings.HasPrefix(source, sourceEnrichedPrefix) && len(source) > len(sourceEnrichedPrefix)
+}
+
+// IsOverridden reports whether the field holds a value that was not guessed by an
+// enrichment provider and therefore must not be replaced by re-enrichment.
+func (p *Person) IsOverridden(field string) bool {
+	switch field {
+	case FieldAge:
+		return !p.AgeSource.IsEnriched()
+	case FieldGender:
+		return !p.GenderSource.IsEnriched()
+	case FieldCountry:
+		return !p.CountrySource.IsEnriched()
+	}
+	return false
 }
 
 type GenderType string
@@ -0,0 +1,49 @@
+package models
+
+import "slices"
+
+const (
+	AuthMethodApiKey = "api_key"
+	AuthMethodJwt    = "jwt"
+)
+
+// Roles grant access to person operations. Each role includes the ones before it: readers
+// get persons, editors also create and update them, admins also delete and run bulk operations.
+const (
+	RoleReader = "reader"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Roles lists the known roles from the least to the most privileged.
+var Roles = []string{RoleReader, RoleEditor, RoleAdmin}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject is the API key name or the sub claim of the token.
+	Subject string
+	// Method is the way the caller authenticated, api_key or jwt.
+	Method string
+	Roles  []string
+}
+
+// Id identifies the caller across authentication methods, an API key and a token subject
+// with the same name are different callers.
+func (p *Principal) Id() string {
+	return p.Method + ":" + p.Subject
+}
+
+// HasRole reports whether one of the principal roles is the required role or a more
+// privileged one.
+func (p *Principal) HasRole(required string) bool {
+	requiredRank := slices.Index(Roles, required)
+	if requiredRank < 0 {
+		return false
+	}
+	for _, role := range p.Roles {
+		if slices.Index(Roles, role) >= requiredRank {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ProviderQuota is the last known rate limit state of an upstream enrichment API.
+type ProviderQuota struct {
+	Provider         string
+	ApiKeyConfigured bool
+
+	// Known is false until the provider has answered with rate limit headers.
+	Known     bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+	UpdatedAt time.Time
+}
+
+// IsExhausted reports whether the provider should not be called before ResetAt.
+func (q ProviderQuota) IsExhausted(now time.Time) bool {
+	return q.Known && q.Remaining <= 0 && now.Before(q.ResetAt)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Rate limit policies. Create covers the routes that change persons or spend the enrichment
+// quota, read covers the rest.
+const (
+	RateLimitPolicyCreate = "create"
+	RateLimitPolicyRead   = "read"
+)
+
+// RateLimit is a token bucket: it holds up to Burst requests and refills Rate of them per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RefillTime is how long an empty bucket takes to become full again.
+func (l RateLimit) RefillTime() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero for allowed requests.
+	RetryAfter time.Duration
+}
+
+// Result describes the bucket that holds tokens after the request was counted.
+func (l RateLimit) Result(tokens float64, allowed bool) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     l.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(l.Burst) - tokens) / l.Rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+	}
+	return result
+}
@@ -0,0 +1,219 @@
+// Package problem turns errors into RFC 7807 application/problem+json responses. Every
+// response carries a stable code clients can branch on and the request id to quote in support
+// requests. Only user errors and a few well-known internal failures are described to the
+// client, the message of any other error stays in the logs.
+package problem
+
+import (
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/requestid"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// typePrefix makes the problem type URI from the code.
+const typePrefix = "urn:effective-mobile:problem:"
+
+// Problem codes. They are part of the API contract and must not change.
+const (
+	CodeMalformedBody        = "malformed_body"
+	CodeValidationFailed     = "validation_failed"
+	CodeInvalidRequest       = "invalid_request"
+	CodePersonNotFound       = "person_not_found"
+	CodeNoFieldsToUpdate     = "no_fields_to_update"
+	CodeInvalidIdempotency   = "invalid_idempotency_key"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeRequestInProgress    = "request_in_progress"
+	CodeUnauthenticated      = "unauthenticated"
+	CodeInvalidApiKey        = "invalid_api_key"
+	CodeInvalidToken         = "invalid_token"
+	CodeForbidden            = "forbidden"
+	CodeRateLimited          = "rate_limited"
+	CodeRouteNotFound        = "route_not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeQuotaExhausted       = "enrichment_quota_exhausted"
+	CodeEnrichmentTimeout    = "enrichment_timeout"
+	CodeEnrichmentFailed     = "enrichment_failed"
+	CodeInternal             = "internal_error"
+)
+
+// Field error codes.
+const (
+	FieldCodeRequired      = "required"
+	FieldCodeInvalidFormat = "invalid_format"
+	FieldCodeInvalidValue  = "invalid_value"
+	FieldCodeOutOfRange    = "out_of_range"
+	FieldCodeInvalidType   = "invalid_type"
+	FieldCodeProviderError = "provider_failed"
+)
+
+// mapping describes how an error is answered. Validation errors name the offending field.
+type mapping struct {
+	err       error
+	status    int
+	code      string
+	field     string
+	fieldCode string
+	// detail replaces the message of internal errors, which is not shown to clients.
+	detail string
+}
+
+// mappings are checked in order with errors.Is, so the enrichment timeout and quota, which
+// arrive wrapped in an EnrichmentError, come before the generic enrichment failure.
+var mappings = []mapping{
+	{err: custom_errors.ErrPersonNotFound, status: http.StatusNotFound, code: CodePersonNotFound},
+	{err: custom_errors.ErrNoFieldsToUpdate, status: http.StatusBadRequest, code: CodeNoFieldsToUpdate},
+
+	{err: custom_errors.ErrEmptyName, status: http.StatusBadRequest, code: CodeValidationFailed, field: "name", fieldCode: FieldCodeRequired},
+	{err: custom_errors.ErrInvalidPersonId, status: http.StatusBadRequest, code: CodeValidationFailed, field: "id", fieldCode: FieldCodeInvalidFormat},
+	{err: custom_errors.ErrInvalidUuid, status: http.StatusBadRequest, code: CodeValidationFailed, field: "ids", fieldCode: FieldCodeInvalidFormat},
+	{err: custom_errors.ErrLimitValue, status: http.StatusBadRequest, code: CodeValidationFailed, field: "limit", fieldCode: FieldCodeOutOfRange},
+	{err: custom_errors.ErrOffsetValue, status: http.StatusBadRequest, code: CodeValidationFailed, field: "offset", fieldCode: FieldCodeOutOfRange},
+	{err: custom_errors.ErrLowAgeValue, status: http.StatusBadRequest, code: CodeValidationFailed, field: "low_age", fieldCode: FieldCodeOutOfRange},
+	{err: custom_errors.ErrHighAgeValue, status: http.StatusBadRequest, code: CodeValidationFailed, field: "high_age", fieldCode: FieldCodeOutOfRange},
+	{err: custom_errors.ErrInvalidGender, status: http.StatusBadRequest, code: CodeValidationFailed, field: "gender", fieldCode: FieldCodeInvalidValue},
+	{err: custom_errors.ErrAgeValue, status: http.StatusBadRequest, code: CodeValidationFailed, field: "age", fieldCode: FieldCodeOutOfRange},
+	{err: custom_errors.ErrInvalidCountry, status: http.StatusBadRequest, code: CodeValidationFailed, field: "country", fieldCode: FieldCodeInvalidFormat},
+	{err: custom_errors.ErrInvalidCountryHint, status: http.StatusBadRequest, code: CodeValidationFailed, field: "country_hint", fieldCode: FieldCodeInvalidFormat},
+	{err: custom_errors.ErrInvalidSource, status: http.StatusBadRequest, code: CodeValidationFailed, field: "sources", fieldCode: FieldCodeInvalidValue},
+
+	{err: custom_errors.ErrInvalidIdempotencyKey, status: http.StatusBadRequest, code: CodeInvalidIdempotency},
+	{err: custom_errors.ErrIdempotencyKeyReused, status: http.StatusUnprocessableEntity, code: CodeIdempotencyKeyReused},
+	{err: custom_errors.ErrIdempotencyRequestInProgress, status: http.StatusConflict, code: CodeRequestInProgress},
+
+	{err: custom_errors.ErrMissingCredentials, status: http.StatusUnauthorized, code: CodeUnauthenticated},
+	{err: custom_errors.ErrInvalidApiKey, status: http.StatusUnauthorized, code: CodeInvalidApiKey},
+	{err: custom_errors.ErrInvalidToken, status: http.StatusUnauthorized, code: CodeInvalidToken},
+	{err: custom_errors.ErrForbidden, status: http.StatusForbidden, code: CodeForbidden},
+	{err: custom_errors.ErrRateLimited, status: http.StatusTooManyRequests, code: CodeRateLimited},
+
+	{err: custom_errors.ErrRouteNotFound, status: http.StatusNotFound, code: CodeRouteNotFound},
+	{err: custom_errors.ErrMethodNotAllowed, status: http.StatusMethodNotAllowed, code: CodeMethodNotAllowed},
+
+	{err: custom_errors.ErrEnrichmentTimeout, status: http.StatusGatewayTimeout, code: CodeEnrichmentTimeout,
+		detail: "enrichment providers did not answer in time, retry later"},
+	{err: custom_errors.ErrQuotaExhausted, status: http.StatusServiceUnavailable, code: CodeQuotaExhausted,
+		detail: "enrichment provider quota is exhausted, retry later"},
+}
+
+// New describes err as a problem of the current request.
+func New(c *gin.Context, err error) dtos.ProblemDto {
+	return complete(c, fromError(err))
+}
+
+// Abort answers the request with the problem describing err and stops the handler chain.
+func Abort(c *gin.Context, err error) {
+	Write(c, New(c, err))
+}
+
+// AbortBind answers a request whose body could not be bound to the DTO. A value of the wrong
+// type is reported as an error of its field, any other decoding error as a malformed body.
+func AbortBind(c *gin.Context, err error) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		Write(c, complete(c, dtos.ProblemDto{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Detail: "request body has a field of the wrong type",
+			Errors: []dtos.FieldErrorDto{{
+				Field:   typeErr.Field,
+				Code:    FieldCodeInvalidType,
+				Message: typeErr.Field + " must be " + typeErr.Type.String(),
+			}},
+		}))
+		return
+	}
+
+	Write(c, complete(c, dtos.ProblemDto{
+		Status: http.StatusBadRequest,
+		Code:   CodeMalformedBody,
+		Detail: custom_errors.ErrMalformedBody.Message + ": " + err.Error(),
+	}))
+}
+
+// Write sends the problem with its status and stops the handler chain.
+func Write(c *gin.Context, problem dtos.ProblemDto) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// NoRoute answers requests to unknown paths.
+func NoRoute(c *gin.Context) {
+	Abort(c, custom_errors.ErrRouteNotFound)
+}
+
+// NoMethod answers requests with a method the path does not support.
+func NoMethod(c *gin.Context) {
+	Abort(c, custom_errors.ErrMethodNotAllowed)
+}
+
+// Recovery answers a request whose handler panicked, for gin.CustomRecovery.
+func Recovery(c *gin.Context, recovered any) {
+	log.Ctx(c.Request.Context()).Error().
+		Interface("panic", recovered).
+		Str("path", c.Request.URL.Path).
+		Msg("Recovered from panic")
+	Abort(c, errors.New("panic"))
+}
+
+func fromError(err error) dtos.ProblemDto {
+	for _, m := range mappings {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+
+		problem := dtos.ProblemDto{Status: m.status, Code: m.code, Detail: m.detail}
+		if problem.Detail == "" {
+			problem.Detail = err.Error()
+		}
+		if m.field != "" {
+			problem.Errors = []dtos.FieldErrorDto{{Field: m.field, Code: m.fieldCode, Message: err.Error()}}
+		}
+		return problem
+	}
+
+	var enrichmentErr *custom_errors.EnrichmentError
+	if errors.As(err, &enrichmentErr) {
+		problem := dtos.ProblemDto{
+			Status: http.StatusBadGateway,
+			Code:   CodeEnrichmentFailed,
+			Detail: "enrichment providers failed, retry later or set the fields manually",
+		}
+		for _, failure := range enrichmentErr.Failures {
+			problem.Errors = append(problem.Errors, dtos.FieldErrorDto{
+				Field:   failure.Field,
+				Code:    FieldCodeProviderError,
+				Message: failure.Provider + " did not return a usable estimate",
+			})
+		}
+		return problem
+	}
+
+	var userErr *custom_errors.UserError
+	if errors.As(err, &userErr) {
+		return dtos.ProblemDto{Status: http.StatusBadRequest, Code: CodeInvalidRequest, Detail: userErr.Error()}
+	}
+
+	return dtos.ProblemDto{
+		Status: http.StatusInternalServerError,
+		Code:   CodeInternal,
+		Detail: "internal server error, quote the request id when reporting it",
+	}
+}
+
+func complete(c *gin.Context, problem dtos.ProblemDto) dtos.ProblemDto {
+	problem.Type = typePrefix + problem.Code
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestId = requestid.FromContext(c.Request.Context())
+	if problem.RequestId == "" {
+		problem.RequestId = c.GetString("RequestID")
+	}
+	return problem
+}
@@ -0,0 +1,140 @@
+package problem
+
+import (
+	"bytes"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"effective-mobile/internal/requestid"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(t *testing.T, handler gin.HandlerFunc, body string) (*httptest.ResponseRecorder, dtos.ProblemDto) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), "req-1"))
+	})
+	router.POST("/persons", handler)
+
+	req, _ := http.NewRequest("POST", "/persons", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem dtos.ProblemDto
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return w, problem
+}
+
+func TestAbort(t *testing.T) {
+	t.Run("Person not found", func(t *testing.T) {
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, custom_errors.ErrPersonNotFound) }, "")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, dtos.ProblemDto{
+			Type:      "urn:effective-mobile:problem:person_not_found",
+			Title:     "Not Found",
+			Status:    http.StatusNotFound,
+			Detail:    "person not found",
+			Instance:  "/persons",
+			Code:      CodePersonNotFound,
+			RequestId: "req-1",
+		}, problem)
+	})
+
+	t.Run("Field validation", func(t *testing.T) {
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, custom_errors.ErrAgeValue) }, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeValidationFailed, problem.Code)
+		assert.Equal(t, []dtos.FieldErrorDto{{
+			Field:   "age",
+			Code:    FieldCodeOutOfRange,
+			Message: custom_errors.ErrAgeValue.Message,
+		}}, problem.Errors)
+	})
+
+	t.Run("No fields to update", func(t *testing.T) {
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, custom_errors.ErrNoFieldsToUpdate) }, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeNoFieldsToUpdate, problem.Code)
+	})
+
+	t.Run("Enrichment timeout wins over the provider failure", func(t *testing.T) {
+		err := &custom_errors.EnrichmentError{Failures: []custom_errors.ProviderFailure{
+			{Field: models.FieldAge, Provider: "agify", Err: custom_errors.ErrEnrichmentTimeout},
+		}}
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, err) }, "")
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.Equal(t, CodeEnrichmentTimeout, problem.Code)
+	})
+
+	t.Run("Enrichment failure lists the fields", func(t *testing.T) {
+		err := &custom_errors.EnrichmentError{Failures: []custom_errors.ProviderFailure{
+			{Field: models.FieldGender, Provider: "genderize", Err: custom_errors.ErrGetGenderStatusCode},
+		}}
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, err) }, "")
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Equal(t, CodeEnrichmentFailed, problem.Code)
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, models.FieldGender, problem.Errors[0].Field)
+		assert.NotContains(t, problem.Errors[0].Message, "status code")
+	})
+
+	t.Run("Internal error does not leak", func(t *testing.T) {
+		err := fmt.Errorf("query failed: %w", &custom_errors.InternalError{Message: "password authentication failed for user app"})
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, err) }, "")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, CodeInternal, problem.Code)
+		assert.NotContains(t, w.Body.String(), "password")
+	})
+
+	t.Run("Unmapped user error", func(t *testing.T) {
+		w, problem := serve(t, func(c *gin.Context) { Abort(c, custom_errors.ErrEmptyApiKeyName) }, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeInvalidRequest, problem.Code)
+		assert.Equal(t, custom_errors.ErrEmptyApiKeyName.Message, problem.Detail)
+	})
+}
+
+func TestAbortBind(t *testing.T) {
+	bind := func(c *gin.Context) {
+		var dto dtos.CreatePersonDto
+		if err := c.ShouldBindJSON(&dto); err != nil {
+			AbortBind(c, err)
+		}
+	}
+
+	t.Run("Wrong field type", func(t *testing.T) {
+		w, problem := serve(t, bind, `{"name": 42}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeValidationFailed, problem.Code)
+		assert.Equal(t, []dtos.FieldErrorDto{{
+			Field:   "name",
+			Code:    FieldCodeInvalidType,
+			Message: "name must be string",
+		}}, problem.Errors)
+	})
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		w, problem := serve(t, bind, `{"name":`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeMalformedBody, problem.Code)
+		assert.Empty(t, problem.Errors)
+	})
+}
@@ -0,0 +1,179 @@
+// Package redact applies the log policies of person fields. Log calls pass person values
+// through Str and Uint32 instead of adding them directly:
+//
+//	log.Info().Func(redact.Str(redact.FieldName, person.Name)).Msg("Person created")
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/rs/zerolog"
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+const (
+	PolicyKeep = "keep"
+	PolicyMask = "mask"
+	PolicyHash = "hash"
+	PolicyDrop = "drop"
+)
+
+// Person fields, used as the log keys of their values.
+const (
+	FieldName       = "name"
+	FieldSurname    = "surname"
+	FieldPatronymic = "patronymic"
+	FieldAge        = "age"
+	FieldGender     = "gender"
+	FieldCountry    = "country"
+)
+
+var (
+	Policies = []string{PolicyKeep, PolicyMask, PolicyHash, PolicyDrop}
+	Fields   = []string{FieldName, FieldSurname, FieldPatronymic, FieldAge, FieldGender, FieldCountry}
+)
+
+// hashLength is the number of hex characters kept from the HMAC, enough to tell values apart
+// in logs.
+const hashLength = 16
+
+type Options struct {
+	// Policies maps a person field to its policy, fields left out keep DefaultPolicies.
+	Policies map[string]string
+	// Salt keys the hash policy. When empty a random salt is used, so hashes can only be
+	// compared within one process.
+	Salt string
+	// Sensitive enables the debug logs of query text, raw payloads and per-field values.
+	Sensitive bool
+}
+
+type redactor struct {
+	policies  map[string]string
+	salt      []byte
+	sensitive bool
+}
+
+var current atomic.Pointer[redactor]
+
+func init() {
+	Configure(Options{})
+}
+
+// DefaultPolicies masks the identifying fields and drops the demographic ones.
+func DefaultPolicies() map[string]string {
+	return map[string]string{
+		FieldName:       PolicyMask,
+		FieldSurname:    PolicyMask,
+		FieldPatronymic: PolicyMask,
+		FieldAge:        PolicyDrop,
+		FieldGender:     PolicyDrop,
+		FieldCountry:    PolicyDrop,
+	}
+}
+
+// Configure replaces the policies used by every following log call.
+func Configure(options Options) {
+	policies := DefaultPolicies()
+	for field, policy := range options.Policies {
+		policies[field] = policy
+	}
+
+	salt := []byte(options.Salt)
+	if len(salt) == 0 {
+		salt = make([]byte, 32)
+		_, _ = rand.Read(salt)
+	}
+
+	current.Store(&redactor{policies: policies, salt: salt, sensitive: options.Sensitive})
+}
+
+// Sensitive reports whether the opt-in debug logs with query text and raw values are enabled.
+func Sensitive() bool {
+	return current.Load().sensitive
+}
+
+// Str adds a person field to the event according to its policy.
+func Str(field string, value string) func(e *zerolog.Event) {
+	return func(e *zerolog.Event) {
+		if redacted, ok := current.Load().apply(field, value); ok {
+			e.Str(field, redacted)
+		}
+	}
+}
+
+// Uint32 is Str for numeric fields, the value stays a number when the policy keeps it.
+func Uint32(field string, value uint32) func(e *zerolog.Event) {
+	return func(e *zerolog.Event) {
+		r := current.Load()
+		switch r.policy(field) {
+		case PolicyKeep:
+			e.Uint32(field, value)
+			return
+		case PolicyMask:
+			// The first digit of an age would still give away the decade.
+			e.Str(field, "*")
+			return
+		}
+		if redacted, ok := r.apply(field, strconv.FormatUint(uint64(value), 10)); ok {
+			e.Str(field, redacted)
+		}
+	}
+}
+
+// SensitiveStr adds the value only when sensitive debug logging is enabled, for query text and
+// raw payloads that may hold any person field.
+func SensitiveStr(key string, value string) func(e *zerolog.Event) {
+	return func(e *zerolog.Event) {
+		if Sensitive() {
+			e.Str(key, value)
+		}
+	}
+}
+
+// SensitiveUint32 is SensitiveStr for numeric values.
+func SensitiveUint32(key string, value uint32) func(e *zerolog.Event) {
+	return func(e *zerolog.Event) {
+		if Sensitive() {
+			e.Uint32(key, value)
+		}
+	}
+}
+
+func (r *redactor) policy(field string) string {
+	if policy, ok := r.policies[field]; ok {
+		return policy
+	}
+	return PolicyMask
+}
+
+// apply returns the value to log and false when the field must be left out.
+func (r *redactor) apply(field string, value string) (string, bool) {
+	switch r.policy(field) {
+	case PolicyKeep:
+		return value, true
+	case PolicyHash:
+		mac := hmac.New(sha256.New, r.salt)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))[:hashLength], true
+	case PolicyDrop:
+		return "", false
+	default:
+		return mask(value), true
+	}
+}
+
+// mask keeps the first character, so support can still tell "I***" from "A***".
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	first, size := utf8.DecodeRuneInString(value)
+	if size == len(value) {
+		return "*"
+	}
+	return string(first) + "***"
+}
@@ -0,0 +1,77 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// logged writes one event built by fields and returns its fields.
+func logged(t *testing.T, fields ...func(e *zerolog.Event)) map[string]any {
+	var buffer bytes.Buffer
+	logger := zerolog.New(&buffer)
+	event := logger.Info()
+	for _, field := range fields {
+		event = event.Func(field)
+	}
+	event.Send()
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &result))
+	return result
+}
+
+func TestRedaction(t *testing.T) {
+	defer Configure(Options{})
+
+	t.Run("Default policies mask names and drop demographics", func(t *testing.T) {
+		Configure(Options{})
+
+		fields := logged(t,
+			Str(FieldName, "Иван"),
+			Str(FieldSurname, "Ivanov"),
+			Uint32(FieldAge, 42),
+			Str(FieldGender, "male"),
+			Str(FieldCountry, "RU"),
+		)
+		assert.Equal(t, "И***", fields[FieldName])
+		assert.Equal(t, "I***", fields[FieldSurname])
+		assert.NotContains(t, fields, FieldAge)
+		assert.NotContains(t, fields, FieldGender)
+		assert.NotContains(t, fields, FieldCountry)
+	})
+
+	t.Run("Configured policies", func(t *testing.T) {
+		Configure(Options{
+			Policies: map[string]string{FieldName: PolicyHash, FieldAge: PolicyKeep, FieldGender: PolicyMask, FieldCountry: PolicyKeep},
+			Salt:     "pepper",
+		})
+
+		fields := logged(t, Str(FieldName, "Ivan"), Uint32(FieldAge, 42), Str(FieldGender, "male"), Str(FieldCountry, "RU"))
+		hash, ok := fields[FieldName].(string)
+		require.True(t, ok)
+		assert.Len(t, hash, hashLength)
+		assert.NotContains(t, hash, "Ivan")
+		assert.Equal(t, hash, logged(t, Str(FieldName, "Ivan"))[FieldName])
+		assert.NotEqual(t, hash, logged(t, Str(FieldName, "Anna"))[FieldName])
+		assert.Equal(t, float64(42), fields[FieldAge])
+		assert.Equal(t, "m***", fields[FieldGender])
+		assert.Equal(t, "RU", fields[FieldCountry])
+
+		Configure(Options{Policies: map[string]string{FieldAge: PolicyMask}})
+		assert.Equal(t, "*", logged(t, Uint32(FieldAge, 42))[FieldAge])
+	})
+
+	t.Run("Sensitive values are opt-in", func(t *testing.T) {
+		Configure(Options{})
+		assert.NotContains(t, logged(t, SensitiveStr("query", "UPDATE persons SET name = $1")), "query")
+		assert.NotContains(t, logged(t, SensitiveUint32(FieldAge, 42)), FieldAge)
+
+		Configure(Options{Sensitive: true})
+		assert.Equal(t, "UPDATE persons SET name = $1", logged(t, SensitiveStr("query", "UPDATE persons SET name = $1"))["query"])
+		assert.Equal(t, float64(42), logged(t, SensitiveUint32(FieldAge, 42))[FieldAge])
+	})
+}
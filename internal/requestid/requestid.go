@@ -0,0 +1,44 @@
+// Package requestid carries the request id and the logger tagged with it in context.Context,
+// so every layer handling the request logs the same request_id.
+package requestid
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"regexp"
+)
+
+const Header = "X-Request-ID"
+
+// maxLength bounds an accepted inbound id, longer ones are replaced like malformed ones.
+const maxLength = 128
+
+// validRegexp allows UUIDs and the usual gateway formats while keeping control characters
+// and spaces out of logs and outbound headers.
+var validRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+type contextKey struct{}
+
+// Valid reports whether an inbound request id can be used as is.
+func Valid(id string) bool {
+	return len(id) <= maxLength && validRegexp.MatchString(id)
+}
+
+func New() string {
+	return uuid.New().String()
+}
+
+// NewContext stores id in ctx together with a logger that adds it to every event, retrieved
+// with log.Ctx.
+func NewContext(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, id)
+	logger := log.Logger.With().Str("request_id", id).Logger()
+	return logger.WithContext(ctx)
+}
+
+// FromContext returns the request id stored in ctx, or an empty string.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,35 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("5f1c3a8e-2b7d-4e0f-9a61-3c2d4b5e6f70"))
+	assert.True(t, Valid("gw:edge-1.42_7"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("id with spaces"))
+	assert.False(t, Valid("id\nforged=log"))
+	assert.False(t, Valid(strings.Repeat("a", maxLength+1)))
+	assert.True(t, Valid(New()))
+}
+
+func TestNewContext(t *testing.T) {
+	var buffer bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buffer)
+	defer func() { log.Logger = previous }()
+
+	ctx := NewContext(context.Background(), "gateway-42")
+	assert.Equal(t, "gateway-42", FromContext(ctx))
+	assert.Equal(t, "", FromContext(context.Background()))
+
+	log.Ctx(ctx).Info().Msg("Creating person in database")
+	assert.Contains(t, buffer.String(), `"request_id":"gateway-42"`)
+}
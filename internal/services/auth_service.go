@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/rs/zerolog/log"
+	"slices"
+	"strings"
+)
+
+const (
+	// apiKeyPrefix marks the keys of the service, so leaked ones are easy to find in code and logs.
+	apiKeyPrefix = "em_"
+	// apiKeyBytes is the entropy of a generated key.
+	apiKeyBytes = 32
+	// apiKeyVisibleLength is how much of the key is kept in the database to recognise it.
+	apiKeyVisibleLength = len(apiKeyPrefix) + 8
+)
+
+type AuthService struct {
+	apiKeyDriver drivers.ApiKeyDriverInterface
+	verifier     *auth.Verifier
+}
+
+// NewAuthService accepts a nil verifier when bearer tokens are not configured.
+func NewAuthService(apiKeyDriver drivers.ApiKeyDriverInterface, verifier *auth.Verifier) *AuthService {
+	log.Debug().Bool("bearer_tokens", verifier != nil).Msg("Initializing AuthService")
+	return &AuthService{apiKeyDriver: apiKeyDriver, verifier: verifier}
+}
+
+func (s *AuthService) AuthenticateApiKey(ctx context.Context, key string) (*models.Principal, error) {
+	log.Ctx(ctx).Debug().Msg("Authenticating api key")
+
+	apiKey, err := s.apiKeyDriver.GetApiKeyByHash(ctx, HashApiKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || apiKey.IsRevoked() {
+		log.Ctx(ctx).Warn().
+			Bool("known", apiKey != nil).
+			Msg(custom_errors.ErrInvalidApiKey.Message)
+		return nil, custom_errors.ErrInvalidApiKey
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("api_key_name", apiKey.Name).
+		Msg("Api key authenticated")
+
+	return &models.Principal{Subject: apiKey.Name, Method: models.AuthMethodApiKey, Roles: apiKey.Roles}, nil
+}
+
+func (s *AuthService) AuthenticateToken(ctx context.Context, token string) (*models.Principal, error) {
+	log.Ctx(ctx).Debug().Msg("Authenticating bearer token")
+
+	if s.verifier == nil {
+		log.Ctx(ctx).Warn().Msg("Bearer token received, but no JWT secret or JWKS file is configured")
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	claims, err := s.verifier.Verify(token)
+	if err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Msg(custom_errors.ErrInvalidToken.Message)
+		return nil, custom_errors.ErrInvalidToken
+	}
+
+	// Roles of other applications may share the claim, only the known ones are kept.
+	roles := make([]string, 0, len(claims.Roles))
+	for _, role := range claims.Roles {
+		if slices.Contains(models.Roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("subject", claims.Subject).
+		Strs("roles", roles).
+		Int("ignored_roles_count", len(claims.Roles)-len(roles)).
+		Msg("Bearer token authenticated")
+
+	return &models.Principal{Subject: claims.Subject, Method: models.AuthMethodJwt, Roles: roles}, nil
+}
+
+// CreateApiKey generates a key with the roles for the client. The key itself is returned only
+// here, the database keeps its hash.
+func (s *AuthService) CreateApiKey(ctx context.Context, name string, roles []string) (string, *models.ApiKey, error) {
+	name = strings.TrimSpace(name)
+	log.Ctx(ctx).Info().
+		Str("api_key_name", name).
+		Strs("roles", roles).
+		Msg("Creating api key")
+
+	if name == "" {
+		log.Ctx(ctx).Warn().Msg(custom_errors.ErrEmptyApiKeyName.Message)
+		return "", nil, custom_errors.ErrEmptyApiKeyName
+	}
+	if len(roles) == 0 {
+		log.Ctx(ctx).Warn().Msg(custom_errors.ErrInvalidRole.Message)
+		return "", nil, custom_errors.ErrInvalidRole
+	}
+	for _, role := range roles {
+		if !slices.Contains(models.Roles, role) {
+			log.Ctx(ctx).Warn().
+				Str("role", role).
+				Msg(custom_errors.ErrInvalidRole.Message)
+			return "", nil, custom_errors.ErrInvalidRole
+		}
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg(custom_errors.ErrCreateApiKey.Message)
+		return "", nil, &custom_errors.InternalError{Message: custom_errors.ErrCreateApiKey.Message, Err: err}
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &models.ApiKey{
+		Name:    name,
+		Prefix:  key[:apiKeyVisibleLength],
+		KeyHash: HashApiKey(key),
+		Roles:   roles,
+	}
+	if err := s.apiKeyDriver.CreateApiKey(ctx, apiKey); err != nil {
+		return "", nil, err
+	}
+
+	return key, apiKey, nil
+}
+
+func (s *AuthService) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	log.Ctx(ctx).Debug().Msg("Listing api keys")
+	return s.apiKeyDriver.ListApiKeys(ctx)
+}
+
+func (s *AuthService) RevokeApiKey(ctx context.Context, name string) error {
+	log.Ctx(ctx).Info().Str("api_key_name", name).Msg("Revoking api key")
+
+	revoked, err := s.apiKeyDriver.RevokeApiKey(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		log.Ctx(ctx).Warn().
+			Str("api_key_name", name).
+			Msg(custom_errors.ErrApiKeyNotFound.Message)
+		return custom_errors.ErrApiKeyNotFound
+	}
+
+	return nil
+}
+
+// HashApiKey returns the hex SHA-256 of the key. Generated keys carry 256 bits of entropy, so
+// a fast hash is enough and lets the key be looked up by its hash.
+func HashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
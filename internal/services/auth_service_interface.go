@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+)
+
+type AuthServiceInterface interface {
+	AuthenticateApiKey(ctx context.Context, key string) (*models.Principal, error)
+	AuthenticateToken(ctx context.Context, token string) (*models.Principal, error)
+	CreateApiKey(ctx context.Context, name string, roles []string) (string, *models.ApiKey, error)
+	ListApiKeys(ctx context.Context) ([]models.ApiKey, error)
+	RevokeApiKey(ctx context.Context, name string) error
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/auth"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MockApiKeyDriver struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyDriver) CreateApiKey(ctx context.Context, apiKey *models.ApiKey) error {
+	args := m.Called(ctx, apiKey)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyDriver) GetApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyDriver) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyDriver) RevokeApiKey(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestAuthenticateApiKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Active key", func(t *testing.T) {
+		mockDriver := new(MockApiKeyDriver)
+		service := NewAuthService(mockDriver, nil)
+		mockDriver.On("GetApiKeyByHash", ctx, HashApiKey("em_key")).Return(&models.ApiKey{Name: "billing", Roles: []string{models.RoleEditor}}, nil).Once()
+
+		principal, err := service.AuthenticateApiKey(ctx, "em_key")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.Principal{Subject: "billing", Method: models.AuthMethodApiKey, Roles: []string{models.RoleEditor}}, principal)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		mockDriver := new(MockApiKeyDriver)
+		service := NewAuthService(mockDriver, nil)
+		mockDriver.On("GetApiKeyByHash", ctx, mock.Anything).Return(nil, nil).Once()
+
+		_, err := service.AuthenticateApiKey(ctx, "em_key")
+
+		assert.ErrorIs(t, err, custom_errors.ErrInvalidApiKey)
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		mockDriver := new(MockApiKeyDriver)
+		service := NewAuthService(mockDriver, nil)
+		revokedAt := time.Now()
+		mockDriver.On("GetApiKeyByHash", ctx, mock.Anything).Return(&models.ApiKey{Name: "billing", RevokedAt: &revokedAt}, nil).Once()
+
+		_, err := service.AuthenticateApiKey(ctx, "em_key")
+
+		assert.ErrorIs(t, err, custom_errors.ErrInvalidApiKey)
+	})
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	ctx := context.Background()
+	secret := "0123456789abcdef0123456789abcdef"
+
+	verifier, err := auth.NewVerifier(auth.VerifierOptions{Secret: secret})
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "service-a",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"offline_access", models.RoleAdmin},
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	t.Run("Valid token", func(t *testing.T) {
+		principal, err := NewAuthService(new(MockApiKeyDriver), verifier).AuthenticateToken(ctx, token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &models.Principal{Subject: "service-a", Method: models.AuthMethodJwt, Roles: []string{models.RoleAdmin}}, principal)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		_, err := NewAuthService(new(MockApiKeyDriver), verifier).AuthenticateToken(ctx, token+"x")
+
+		assert.ErrorIs(t, err, custom_errors.ErrInvalidToken)
+	})
+
+	t.Run("Tokens not configured", func(t *testing.T) {
+		_, err := NewAuthService(new(MockApiKeyDriver), nil).AuthenticateToken(ctx, token)
+
+		assert.ErrorIs(t, err, custom_errors.ErrInvalidToken)
+	})
+}
+
+func TestCreateApiKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores the hash only", func(t *testing.T) {
+		mockDriver := new(MockApiKeyDriver)
+		service := NewAuthService(mockDriver, nil)
+		mockDriver.On("CreateApiKey", ctx, mock.AnythingOfType("*models.ApiKey")).Return(nil).Once()
+
+		key, apiKey, err := service.CreateApiKey(ctx, " billing ", []string{models.RoleEditor})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.RoleEditor}, apiKey.Roles)
+		assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+		assert.Equal(t, "billing", apiKey.Name)
+		assert.Equal(t, key[:apiKeyVisibleLength], apiKey.Prefix)
+		assert.Equal(t, HashApiKey(key), apiKey.KeyHash)
+		assert.NotContains(t, apiKey.KeyHash, key)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		_, _, err := NewAuthService(new(MockApiKeyDriver), nil).CreateApiKey(ctx, " ", []string{models.RoleReader})
+
+		assert.ErrorIs(t, err, custom_errors.ErrEmptyApiKeyName)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		_, _, err := NewAuthService(new(MockApiKeyDriver), nil).CreateApiKey(ctx, "billing", []string{"owner"})
+
+		assert.ErrorIs(t, err, custom_errors.ErrInvalidRole)
+	})
+
+	t.Run("No roles", func(t *testing.T) {
+		_, _, err := NewAuthService(new(MockApiKeyDriver), nil).CreateApiKey(ctx, "billing", nil)
+
+		assert.ErrorIs(t, err, custom_errors.ErrInvalidRole)
+	})
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	ctx := context.Background()
+	mockDriver := new(MockApiKeyDriver)
+	service := NewAuthService(mockDriver, nil)
+
+	mockDriver.On("RevokeApiKey", ctx, "billing").Return(true, nil).Once()
+	mockDriver.On("RevokeApiKey", ctx, "unknown").Return(false, nil).Once()
+
+	assert.NoError(t, service.RevokeApiKey(ctx, "billing"))
+	assert.ErrorIs(t, service.RevokeApiKey(ctx, "unknown"), custom_errors.ErrApiKeyNotFound)
+	mockDriver.AssertExpectations(t)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/enrichment"
+	"github.com/rs/zerolog/log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOk       = "ok"
+	statusReady    = "ready"
+	statusNotReady = "not_ready"
+
+	dependencyUp       = "up"
+	dependencyDown     = "down"
+	dependencyDegraded = "degraded"
+
+	circuitClosed = "closed"
+	circuitOpen   = "open"
+)
+
+// readinessCheckTimeout bounds every dependency check, so a hanging database fails the
+// probe instead of timing it out.
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthService struct {
+	healthDriver drivers.HealthDriverInterface
+	quotaTracker enrichment.QuotaTrackerInterface
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(healthDriver drivers.HealthDriverInterface, quotaTracker enrichment.QuotaTrackerInterface) *HealthService {
+	log.Debug().Msg("Initializing HealthService")
+	return &HealthService{healthDriver: healthDriver, quotaTracker: quotaTracker}
+}
+
+// Liveness only tells that the process is able to serve requests, it never looks at
+// dependencies so an outage of Postgres does not get the pod restarted.
+func (s *HealthService) Liveness(_ context.Context) dtos.HealthDto {
+	return dtos.HealthDto{Status: statusOk, Timestamp: time.Now().Unix()}
+}
+
+// Readiness checks the database, pending migrations and enrichment providers. It reports
+// false when a critical dependency is down or the server is shutting down. Providers are not
+// critical: an open circuit only disables one source, the chains fall back to the others.
+func (s *HealthService) Readiness(ctx context.Context) (dtos.ReadinessDto, bool) {
+	readiness := dtos.ReadinessDto{
+		Status:       statusReady,
+		ShuttingDown: s.shuttingDown.Load(),
+		Timestamp:    time.Now().Unix(),
+	}
+
+	readiness.Checks = append(readiness.Checks, s.checkDatabase(ctx), s.checkMigrations(ctx))
+	readiness.Checks = append(readiness.Checks, s.checkProviders()...)
+
+	ready := !readiness.ShuttingDown
+	for _, check := range readiness.Checks {
+		if check.Critical && check.Status == dependencyDown {
+			ready = false
+		}
+	}
+	if !ready {
+		readiness.Status = statusNotReady
+	}
+
+	log.Ctx(ctx).Debug().
+		Bool("ready", ready).
+		Bool("shutting_down", readiness.ShuttingDown).
+		Int("checks_count", len(readiness.Checks)).
+		Msg("Readiness checked")
+
+	return readiness, ready
+}
+
+// SetShuttingDown makes every following readiness check fail, so load balancers stop
+// sending traffic before the server stops accepting connections.
+func (s *HealthService) SetShuttingDown() {
+	s.shuttingDown.Store(true)
+	log.Info().Msg("Readiness switched to not ready for shutdown")
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) dtos.DependencyCheckDto {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	check := dtos.DependencyCheckDto{Name: "database", Status: dependencyUp, Critical: true}
+	startedAt := time.Now()
+	err := s.healthDriver.Ping(ctx)
+	check.LatencyMs = time.Since(startedAt).Milliseconds()
+	if err != nil {
+		check.Status = dependencyDown
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) dtos.DependencyCheckDto {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	check := dtos.DependencyCheckDto{Name: "migrations", Status: dependencyUp, Critical: true}
+	startedAt := time.Now()
+	pending, err := s.healthDriver.HasPendingMigrations(ctx)
+	check.LatencyMs = time.Since(startedAt).Milliseconds()
+	switch {
+	case err != nil:
+		check.Status = dependencyDown
+		check.Error = err.Error()
+	case pending:
+		check.Status = dependencyDown
+		check.Error = "database schema has pending migrations"
+	}
+	return check
+}
+
+// checkProviders reports a provider circuit as open while its quota is exhausted: the
+// enricher does not call it until the quota resets.
+func (s *HealthService) checkProviders() []dtos.DependencyCheckDto {
+	now := time.Now()
+	quotas := s.quotaTracker.Quotas()
+
+	checks := make([]dtos.DependencyCheckDto, 0, len(quotas))
+	for _, quota := range quotas {
+		check := dtos.DependencyCheckDto{
+			Name:    "provider:" + quota.Provider,
+			Status:  dependencyUp,
+			Circuit: circuitClosed,
+		}
+		if quota.IsExhausted(now) {
+			resetAt := quota.ResetAt
+			check.Status = dependencyDegraded
+			check.Circuit = circuitOpen
+			check.Error = "quota exhausted"
+			check.RetryAt = &resetAt
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+)
+
+type HealthServiceInterface interface {
+	Liveness(ctx context.Context) dtos.HealthDto
+	Readiness(ctx context.Context) (dtos.ReadinessDto, bool)
+	SetShuttingDown()
+}
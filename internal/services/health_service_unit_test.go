@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type MockHealthDriver struct {
+	mock.Mock
+}
+
+func (m *MockHealthDriver) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockHealthDriver) HasPendingMigrations(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestReadiness(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Ready with open provider circuit", func(t *testing.T) {
+		mockDriver := new(MockHealthDriver)
+		mockTracker := new(MockQuotaTracker)
+		service := NewHealthService(mockDriver, mockTracker)
+
+		resetAt := time.Now().Add(time.Hour)
+		mockDriver.On("Ping", mock.Anything).Return(nil)
+		mockDriver.On("HasPendingMigrations", mock.Anything).Return(false, nil)
+		mockTracker.On("Quotas").Return([]models.ProviderQuota{
+			{Provider: "agify"},
+			{Provider: "genderize", Known: true, Limit: 100, Remaining: 0, ResetAt: resetAt},
+		})
+
+		readiness, ready := service.Readiness(ctx)
+		assert.True(t, ready)
+		assert.Equal(t, statusReady, readiness.Status)
+		require.Len(t, readiness.Checks, 4)
+		assert.Equal(t, "database", readiness.Checks[0].Name)
+		assert.Equal(t, dependencyUp, readiness.Checks[0].Status)
+		assert.Equal(t, circuitClosed, readiness.Checks[2].Circuit)
+		assert.Equal(t, "provider:genderize", readiness.Checks[3].Name)
+		assert.Equal(t, dependencyDegraded, readiness.Checks[3].Status)
+		assert.Equal(t, circuitOpen, readiness.Checks[3].Circuit)
+		assert.Equal(t, resetAt, *readiness.Checks[3].RetryAt)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Not ready when database is down", func(t *testing.T) {
+		mockDriver := new(MockHealthDriver)
+		mockTracker := new(MockQuotaTracker)
+		service := NewHealthService(mockDriver, mockTracker)
+
+		mockDriver.On("Ping", mock.Anything).Return(custom_errors.ErrPingDatabase)
+		mockDriver.On("HasPendingMigrations", mock.Anything).Return(false, custom_errors.ErrGetMigrationStatus)
+		mockTracker.On("Quotas").Return([]models.ProviderQuota{})
+
+		readiness, ready := service.Readiness(ctx)
+		assert.False(t, ready)
+		assert.Equal(t, statusNotReady, readiness.Status)
+		assert.Equal(t, dependencyDown, readiness.Checks[0].Status)
+		assert.Equal(t, custom_errors.ErrPingDatabase.Error(), readiness.Checks[0].Error)
+		assert.Equal(t, dependencyDown, readiness.Checks[1].Status)
+	})
+
+	t.Run("Not ready with pending migrations", func(t *testing.T) {
+		mockDriver := new(MockHealthDriver)
+		mockTracker := new(MockQuotaTracker)
+		service := NewHealthService(mockDriver, mockTracker)
+
+		mockDriver.On("Ping", mock.Anything).Return(nil)
+		mockDriver.On("HasPendingMigrations", mock.Anything).Return(true, nil)
+		mockTracker.On("Quotas").Return([]models.ProviderQuota{})
+
+		readiness, ready := service.Readiness(ctx)
+		assert.False(t, ready)
+		assert.Equal(t, dependencyUp, readiness.Checks[0].Status)
+		assert.Equal(t, dependencyDown, readiness.Checks[1].Status)
+	})
+
+	t.Run("Not ready while shutting down", func(t *testing.T) {
+		mockDriver := new(MockHealthDriver)
+		mockTracker := new(MockQuotaTracker)
+		service := NewHealthService(mockDriver, mockTracker)
+
+		mockDriver.On("Ping", mock.Anything).Return(nil)
+		mockDriver.On("HasPendingMigrations", mock.Anything).Return(false, nil)
+		mockTracker.On("Quotas").Return([]models.ProviderQuota{})
+
+		service.SetShuttingDown()
+		readiness, ready := service.Readiness(ctx)
+		assert.False(t, ready)
+		assert.True(t, readiness.ShuttingDown)
+		assert.Equal(t, statusNotReady, readiness.Status)
+
+		assert.Equal(t, statusOk, service.Liveness(ctx).Status)
+	})
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"encoding/hex"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService struct {
+	idempotencyDriver drivers.IdempotencyDriverInterface
+	ttl               time.Duration
+}
+
+func NewIdempotencyService(idempotencyDriver drivers.IdempotencyDriverInterface, ttl time.Duration) *IdempotencyService {
+	log.Debug().Dur("ttl", ttl).Msg("Initializing IdempotencyService")
+	return &IdempotencyService{idempotencyDriver: idempotencyDriver, ttl: ttl}
+}
+
+// Begin reserves the key of the principal for the current request. It returns nil when the
+// caller should process the request, or the stored record when the response must be replayed.
+// Keys of other principals are never looked at, so reusing one is not an error.
+func (s *IdempotencyService) Begin(ctx context.Context, principal, key, requestHash string) (*models.IdempotencyRecord, error) {
+	log.Ctx(ctx).Debug().Str("idempotency_key", key).Msg("Beginning idempotent request")
+
+	if len(key) == 0 || len(key) > maxIdempotencyKeyLength {
+		log.Ctx(ctx).Warn().
+			Int("key_length", len(key)).
+			Msg(custom_errors.ErrInvalidIdempotencyKey.Message)
+		return nil, custom_errors.ErrInvalidIdempotencyKey
+	}
+
+	reserved, err := s.idempotencyDriver.ReserveIdempotencyKey(ctx, principal, key, requestHash, time.Now().Add(s.ttl))
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		log.Ctx(ctx).Debug().Str("idempotency_key", key).Msg("Idempotency key reserved, processing request")
+		return nil, nil
+	}
+
+	record, err := s.idempotencyDriver.GetIdempotencyKey(ctx, principal, key)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		log.Ctx(ctx).Warn().
+			Str("idempotency_key", key).
+			Msg("Idempotency key expired or was released concurrently")
+		return nil, custom_errors.ErrIdempotencyRequestInProgress
+	}
+
+	if record.RequestHash != requestHash {
+		log.Ctx(ctx).Warn().
+			Str("idempotency_key", key).
+			Msg(custom_errors.ErrIdempotencyKeyReused.Message)
+		return nil, custom_errors.ErrIdempotencyKeyReused
+	}
+
+	if !record.IsCompleted() {
+		log.Ctx(ctx).Warn().
+			Str("idempotency_key", key).
+			Msg(custom_errors.ErrIdempotencyRequestInProgress.Message)
+		return nil, custom_errors.ErrIdempotencyRequestInProgress
+	}
+
+	log.Ctx(ctx).Info().
+		Str("idempotency_key", key).
+		Int("status_code", record.StatusCode).
+		Msg("Replaying stored response for idempotency key")
+
+	return record, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, principal, key string, statusCode int, contentType string, body []byte) error {
+	log.Ctx(ctx).Debug().
+		Str("idempotency_key", key).
+		Int("status_code", statusCode).
+		Msg("Completing idempotent request")
+
+	return s.idempotencyDriver.CompleteIdempotencyKey(ctx, &models.IdempotencyRecord{
+		Principal:    principal,
+		Key:          key,
+		StatusCode:   statusCode,
+		ContentType:  contentType,
+		ResponseBody: body,
+	})
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, principal, key string) error {
+	log.Ctx(ctx).Debug().Str("idempotency_key", key).Msg("Releasing idempotency key")
+	return s.idempotencyDriver.DeleteIdempotencyKey(ctx, principal, key)
+}
+
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) error {
+	deleted, err := s.idempotencyDriver.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted_count", deleted).Msg("Expired idempotency keys purged")
+	return nil
+}
+
+func HashRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
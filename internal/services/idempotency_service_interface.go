@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+)
+
+type IdempotencyServiceInterface interface {
+	Begin(ctx context.Context, principal, key, requestHash string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, principal, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, principal, key string) error
+	PurgeExpired(ctx context.Context) error
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MockIdempotencyDriver struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyDriver) ReserveIdempotencyKey(ctx context.Context, principal, key, requestHash string, expiresAt time.Time) (bool, error) {
+	args := m.Called(ctx, principal, key, requestHash, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyDriver) GetIdempotencyKey(ctx context.Context, principal, key string) (*models.IdempotencyRecord, error) {
+	args := m.Called(ctx, principal, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyDriver) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyDriver) DeleteIdempotencyKey(ctx context.Context, principal, key string) error {
+	args := m.Called(ctx, principal, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyDriver) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyBegin(t *testing.T) {
+	ctx := context.Background()
+	principal := "api_key:ci"
+	key := "key"
+	requestHash := HashRequest("POST", "/persons", []byte(`{"name":"Ivan","surname":"Ivanov"}`))
+
+	t.Run("Begin with new key", func(t *testing.T) {
+		mockDriver := new(MockIdempotencyDriver)
+		service := NewIdempotencyService(mockDriver, time.Hour)
+
+		mockDriver.On("ReserveIdempotencyKey", mock.Anything, principal, key, requestHash, mock.Anything).Return(true, nil)
+
+		record, err := service.Begin(ctx, principal, key, requestHash)
+		assert.NoError(t, err)
+		assert.Nil(t, record)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Begin with completed key and same request", func(t *testing.T) {
+		mockDriver := new(MockIdempotencyDriver)
+		service := NewIdempotencyService(mockDriver, time.Hour)
+
+		stored := &models.IdempotencyRecord{
+			Principal:    principal,
+			Key:          key,
+			RequestHash:  requestHash,
+			StatusCode:   201,
+			ContentType:  "application/json; charset=utf-8",
+			ResponseBody: []byte(`{"id":"id"}`),
+		}
+		mockDriver.On("ReserveIdempotencyKey", mock.Anything, principal, key, requestHash, mock.Anything).Return(false, nil)
+		mockDriver.On("GetIdempotencyKey", mock.Anything, principal, key).Return(stored, nil)
+
+		record, err := service.Begin(ctx, principal, key, requestHash)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, record)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Begin with key reused for different request", func(t *testing.T) {
+		mockDriver := new(MockIdempotencyDriver)
+		service := NewIdempotencyService(mockDriver, time.Hour)
+
+		otherHash := HashRequest("POST", "/persons", []byte(`{"name":"Anna","surname":"Ivanova"}`))
+		mockDriver.On("ReserveIdempotencyKey", mock.Anything, principal, key, otherHash, mock.Anything).Return(false, nil)
+		mockDriver.On("GetIdempotencyKey", mock.Anything, principal, key).Return(&models.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			StatusCode:  201,
+		}, nil)
+
+		record, err := service.Begin(ctx, principal, key, otherHash)
+		assert.Error(t, err)
+		assert.Nil(t, record)
+		assert.Equal(t, custom_errors.ErrIdempotencyKeyReused, err)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Begin with key still in progress", func(t *testing.T) {
+		mockDriver := new(MockIdempotencyDriver)
+		service := NewIdempotencyService(mockDriver, time.Hour)
+
+		mockDriver.On("ReserveIdempotencyKey", mock.Anything, principal, key, requestHash, mock.Anything).Return(false, nil)
+		mockDriver.On("GetIdempotencyKey", mock.Anything, principal, key).Return(&models.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+		}, nil)
+
+		record, err := service.Begin(ctx, principal, key, requestHash)
+		assert.Error(t, err)
+		assert.Nil(t, record)
+		assert.Equal(t, custom_errors.ErrIdempotencyRequestInProgress, err)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Begin with too long key", func(t *testing.T) {
+		mockDriver := new(MockIdempotencyDriver)
+		service := NewIdempotencyService(mockDriver, time.Hour)
+
+		record, err := service.Begin(ctx, principal, strings.Repeat("k", maxIdempotencyKeyLength+1), requestHash)
+		assert.Error(t, err)
+		assert.Nil(t, record)
+		assert.Equal(t, custom_errors.ErrInvalidIdempotencyKey, err)
+		mockDriver.AssertExpectations(t)
+	})
+}
@@ -4,144 +4,143 @@ import (
 	"context"
 	"effective-mobile/internal/drivers"
 	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/enrichment"
 	"effective-mobile/internal/models"
 	"effective-mobile/internal/models/custom_errors"
-	"encoding/json"
+	"effective-mobile/internal/redact"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog/log"
-	"io"
-	"net/http"
-	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const maxAge = 150
+
+// MaxEnrichBatchSize bounds the number of persons re-enriched by one EnrichPersons call.
+const MaxEnrichBatchSize = 20
+
+var countryCodeRegexp = regexp.MustCompile(`^[A-Z]{2}$`)
+
 type PersonService struct {
 	personDriver drivers.PersonDriverInterface
+	enricher     enrichment.EnricherInterface
 }
 
-func NewPersonService(personDriver drivers.PersonDriverInterface) *PersonService {
+func NewPersonService(personDriver drivers.PersonDriverInterface, enricher enrichment.EnricherInterface) *PersonService {
 	log.Debug().Msg("Initializing PersonService")
-	return &PersonService{personDriver: personDriver}
+	return &PersonService{personDriver: personDriver, enricher: enricher}
 }
 
 func (s *PersonService) CreatePerson(ctx context.Context, personDto dtos.CreatePersonDto) (*dtos.PersonDto, error) {
-	log.Info().
-		Str("name", personDto.Name).
-		Str("surname", personDto.Surname).
+	log.Ctx(ctx).Info().
+		Func(redact.Str(redact.FieldName, personDto.Name)).
+		Func(redact.Str(redact.FieldSurname, personDto.Surname)).
 		Bool("has_patronymic", personDto.Patronymic != nil).
 		Msg("Creating new person")
 
-	personId := generateUuid()
-	log.Debug().Str("generated_uuid", personId.String()).Msg("Generated UUID for new person")
+	personDto.Country = normalizeCountryCode(personDto.Country)
+	personDto.CountryHint = normalizeCountryCode(personDto.CountryHint)
 
-	ageChan := make(chan uint32, 1)
-	ageErrChan := make(chan error, 1)
-
-	genderChan := make(chan models.GenderType, 1)
-	genderErrChan := make(chan error, 1)
-
-	countryChan := make(chan string, 1)
-	countryErrChan := make(chan error, 1)
+	log.Ctx(ctx).Debug().Msg("Validating supplied person attributes")
+	if err := validatePersonAttributes(ctx, personDto.Age, personDto.Gender, personDto.Country); err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Msg("Invalid person attributes")
+		return nil, err
+	}
 
-	log.Debug().Msg("Starting goroutines to fetch person attributes")
+	if err := validateCountryHint(ctx, personDto.CountryHint); err != nil {
+		return nil, err
+	}
 
-	go func() {
-		log.Debug().Str("name", personDto.Name).Msg("Fetching age")
-		age, err := getAge(personDto.Name)
-		ageChan <- age
-		ageErrChan <- err
-		if err == nil {
-			log.Debug().Str("name", personDto.Name).Uint32("age", age).Msg("Age fetched successfully")
-		}
-	}()
-
-	go func() {
-		log.Debug().Str("name", personDto.Name).Msg("Fetching gender")
-		gender, err := getGender(personDto.Name)
-		genderChan <- gender
-		genderErrChan <- err
-		if err == nil {
-			log.Debug().Str("name", personDto.Name).Str("gender", string(gender)).Msg("Gender fetched successfully")
+	personId := generateUuid()
+	log.Ctx(ctx).Debug().Str("generated_uuid", personId.String()).Msg("Generated UUID for new person")
+
+	now := time.Now()
+	person := &models.Person{Id: personId, Name: personDto.Name, Surname: personDto.Surname}
+	missingFields := make([]string, 0)
+	var results []models.ProviderResult
+
+	if personDto.Age != nil {
+		person.Age = *personDto.Age
+		person.AgeSource = models.AttributeSource{Source: models.SourceImport, UpdatedAt: now}
+	} else {
+		missingFields = append(missingFields, models.FieldAge)
+	}
+
+	if personDto.Gender != nil {
+		person.Gender = models.GenderType(*personDto.Gender)
+		person.GenderSource = models.AttributeSource{Source: models.SourceImport, UpdatedAt: now}
+	} else {
+		missingFields = append(missingFields, models.FieldGender)
+	}
+
+	if personDto.Country != nil {
+		person.Country = *personDto.Country
+		person.CountrySource = models.AttributeSource{Source: models.SourceImport, UpdatedAt: now}
+	} else {
+		missingFields = append(missingFields, models.FieldCountry)
+	}
+
+	if len(missingFields) > 0 {
+		log.Ctx(ctx).Debug().
+			Func(redact.Str(redact.FieldName, personDto.Name)).
+			Strs("fields", missingFields).
+			Msg("Enriching missing person attributes")
+
+		enriched, err := s.enricher.Enrich(ctx, newEnrichmentRequest(personDto, missingFields))
+		if err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Func(redact.Str(redact.FieldName, personDto.Name)).
+				Msg("Failed to enrich person attributes")
+			return nil, err
 		}
-	}()
-
-	go func() {
-		log.Debug().Str("name", personDto.Name).Msg("Fetching country")
-		country, err := getCountry(personDto.Name)
-		countryChan <- country
-		countryErrChan <- err
-		if err == nil {
-			log.Debug().Str("name", personDto.Name).Str("country", country).Msg("Country fetched successfully")
+
+		for _, field := range missingFields {
+			setEnrichedField(person, enriched, field, now)
 		}
-	}()
-
-	log.Debug().Msg("Waiting for age result")
-	age := <-ageChan
-	ageErr := <-ageErrChan
-	if ageErr != nil {
-		log.Error().
-			Err(ageErr).
-			Str("name", personDto.Name).
-			Msg("Failed to get age")
-		return nil, ageErr
-	}
-
-	log.Debug().Msg("Waiting for gender result")
-	gender := <-genderChan
-	genderErr := <-genderErrChan
-	if genderErr != nil {
-		log.Error().
-			Err(genderErr).
-			Str("name", personDto.Name).
-			Msg("Failed to get gender")
-		return nil, genderErr
-	}
-
-	log.Debug().Msg("Waiting for country result")
-	country := <-countryChan
-	countryErr := <-countryErrChan
-	if countryErr != nil {
-		log.Error().
-			Err(countryErr).
-			Str("name", personDto.Name).
-			Msg("Failed to get country")
-		return nil, countryErr
-	}
-
-	log.Debug().
+		results = enriched.Results
+	} else {
+		log.Ctx(ctx).Debug().Msg("All attributes supplied, skipping enrichment")
+	}
+
+	log.Ctx(ctx).Debug().
 		Str("person_id", personId.String()).
-		Str("name", personDto.Name).
-		Str("surname", personDto.Surname).
-		Uint32("age", age).
-		Str("country", country).
-		Str("gender", string(gender)).
+		Func(redact.Str(redact.FieldName, personDto.Name)).
+		Func(redact.Str(redact.FieldSurname, personDto.Surname)).
+		Func(redact.Uint32(redact.FieldAge, person.Age)).
+		Func(redact.Str(redact.FieldCountry, person.Country)).
+		Func(redact.Str(redact.FieldGender, string(person.Gender))).
 		Msg("Prepared person data")
 
-	person := &models.Person{Id: personId, Name: personDto.Name, Surname: personDto.Surname, Age: age, Country: country, Gender: gender}
 	if personDto.Patronymic != nil {
 		person.Patronymic = *personDto.Patronymic
-		log.Debug().
+		log.Ctx(ctx).Debug().
 			Str("person_id", personId.String()).
-			Str("patronymic", *personDto.Patronymic).
+			Func(redact.Str(redact.FieldPatronymic, *personDto.Patronymic)).
 			Msg("Added patronymic to person")
 	}
 
-	log.Debug().Str("person_id", personId.String()).Msg("Saving person to database")
-	if err := s.personDriver.CreatePerson(ctx, person); err != nil {
-		log.Error().
+	log.Ctx(ctx).Debug().Str("person_id", personId.String()).Msg("Saving person to database")
+	if err := s.personDriver.CreatePerson(ctx, person, results); err != nil {
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personId.String()).
 			Msg("Failed to save person to database")
 		return nil, err
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personId.String()).
-		Str("name", person.Name).
-		Str("surname", person.Surname).
-		Uint32("age", person.Age).
-		Str("country", person.Country).
-		Str("gender", string(person.Gender)).
+		Func(redact.Str(redact.FieldName, person.Name)).
+		Func(redact.Str(redact.FieldSurname, person.Surname)).
+		Func(redact.Uint32(redact.FieldAge, person.Age)).
+		Func(redact.Str(redact.FieldCountry, person.Country)).
+		Func(redact.Str(redact.FieldGender, string(person.Gender))).
 		Msg("Person created successfully")
 
 	createdPersonDto := mapPersonToDto(person)
@@ -150,85 +149,86 @@ func (s *PersonService) CreatePerson(ctx context.Context, personDto dtos.CreateP
 }
 
 func (s *PersonService) UpdatePerson(ctx context.Context, personDto dtos.PersonDto) (*dtos.PersonDto, error) {
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personDto.Id.String()).
 		Msg("Updating person")
 
-	log.Debug().Str("person_id", personDto.Id.String()).Msg("Checking if person exists")
+	personDto.Country = normalizeCountryCode(personDto.Country)
+
+	log.Ctx(ctx).Debug().Str("person_id", personDto.Id.String()).Msg("Validating updated person attributes")
+	if err := validatePersonAttributes(ctx, personDto.Age, personDto.Gender, personDto.Country); err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Str("person_id", personDto.Id.String()).
+			Msg("Invalid person attributes")
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().Str("person_id", personDto.Id.String()).Msg("Checking if person exists")
 	_, err := s.GetPersonById(ctx, personDto.Id)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personDto.Id.String()).
 			Msg("Person not found for update")
 		return nil, err
 	}
 
-	log.Debug().Str("person_id", personDto.Id.String()).Msg("Updating person in database")
+	log.Ctx(ctx).Debug().Str("person_id", personDto.Id.String()).Msg("Updating person in database")
 	updatedPerson, err := s.personDriver.UpdatePerson(ctx, personDto)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personDto.Id.String()).
 			Msg("Failed to update person in database")
 		return nil, err
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personDto.Id.String()).
-		Str("name", updatedPerson.Name).
-		Str("surname", updatedPerson.Surname).
-		Uint32("age", updatedPerson.Age).
-		Str("country", updatedPerson.Country).
-		Str("gender", string(updatedPerson.Gender)).
+		Func(redact.Str(redact.FieldName, updatedPerson.Name)).
+		Func(redact.Str(redact.FieldSurname, updatedPerson.Surname)).
+		Func(redact.Uint32(redact.FieldAge, updatedPerson.Age)).
+		Func(redact.Str(redact.FieldCountry, updatedPerson.Country)).
+		Func(redact.Str(redact.FieldGender, string(updatedPerson.Gender))).
 		Msg("Person updated successfully")
 
-	genderDto := string(updatedPerson.Gender)
-
-	updatedPersonDto := &dtos.PersonDto{
-		Id:         updatedPerson.Id,
-		Name:       &updatedPerson.Name,
-		Surname:    &updatedPerson.Surname,
-		Patronymic: &updatedPerson.Patronymic,
-		Age:        &updatedPerson.Age,
-		Gender:     &genderDto,
-		Country:    &updatedPerson.Country,
-	}
+	updatedPersonDto := mapPersonToDto(updatedPerson)
 	return updatedPersonDto, nil
 }
 
 func (s *PersonService) DeletePerson(ctx context.Context, personId pgtype.UUID) error {
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personId.String()).
 		Msg("Deleting person")
 
-	log.Debug().Str("person_id", personId.String()).Msg("Checking if person exists")
+	log.Ctx(ctx).Debug().Str("person_id", personId.String()).Msg("Checking if person exists")
 	person, err := s.GetPersonById(ctx, personId)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personId.String()).
 			Msg("Person not found for deletion")
 		return err
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("person_id", personId.String()).
-		Str("name", *person.Name).
-		Str("surname", *person.Surname).
+		Func(redact.Str(redact.FieldName, *person.Name)).
+		Func(redact.Str(redact.FieldSurname, *person.Surname)).
 		Msg("Found person to delete")
 
-	log.Debug().Str("person_id", personId.String()).Msg("Deleting person from database")
+	log.Ctx(ctx).Debug().Str("person_id", personId.String()).Msg("Deleting person from database")
 	err = s.personDriver.DeletePerson(ctx, personId)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personId.String()).
 			Msg("Failed to delete person from database")
 		return err
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personId.String()).
 		Msg("Person deleted successfully")
 
@@ -236,32 +236,32 @@ func (s *PersonService) DeletePerson(ctx context.Context, personId pgtype.UUID)
 }
 
 func (s *PersonService) GetPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) ([]dtos.PersonDto, error) {
-	log.Info().Msg("Getting persons with filters")
+	log.Ctx(ctx).Info().Msg("Getting persons with filters")
 
-	log.Debug().Msg("Validating filter parameters")
-	if err := validateGetPersonDto(getPersonDto); err != nil {
-		log.Warn().
+	log.Ctx(ctx).Debug().Msg("Validating filter parameters")
+	if err := validateGetPersonDto(ctx, getPersonDto); err != nil {
+		log.Ctx(ctx).Warn().
 			Err(err).
 			Msg("Invalid filter parameters")
 		return nil, err
 	}
 
-	log.Debug().Msg("Fetching persons from database")
+	log.Ctx(ctx).Debug().Msg("Fetching persons from database")
 	persons, err := s.personDriver.GetPersons(ctx, getPersonDto)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Msg("Failed to get persons from database")
 		return nil, err
 	}
 
-	log.Debug().Int("count", len(persons)).Msg("Converting persons to DTOs")
+	log.Ctx(ctx).Debug().Int("count", len(persons)).Msg("Converting persons to DTOs")
 	personDtos := make([]dtos.PersonDto, len(persons))
 	for i, person := range persons {
 		personDtos[i] = *mapPersonToDto(&person)
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Int("count", len(personDtos)).
 		Msg("Persons retrieved successfully")
 
@@ -269,27 +269,27 @@ func (s *PersonService) GetPersons(ctx context.Context, getPersonDto dtos.GetPer
 }
 
 func (s *PersonService) GetPersonById(ctx context.Context, personId pgtype.UUID) (*dtos.PersonDto, error) {
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personId.String()).
 		Msg("Getting person by ID")
 
-	log.Debug().Str("person_id", personId.String()).Msg("Fetching person from database")
+	log.Ctx(ctx).Debug().Str("person_id", personId.String()).Msg("Fetching person from database")
 	person, err := s.personDriver.GetPersonById(ctx, personId)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("person_id", personId.String()).
 			Msg("Failed to get person from database")
 		return nil, err
 	}
 
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("person_id", personId.String()).
-		Str("name", person.Name).
-		Str("surname", person.Surname).
-		Uint32("age", person.Age).
-		Str("country", person.Country).
-		Str("gender", string(person.Gender)).
+		Func(redact.Str(redact.FieldName, person.Name)).
+		Func(redact.Str(redact.FieldSurname, person.Surname)).
+		Func(redact.Uint32(redact.FieldAge, person.Age)).
+		Func(redact.Str(redact.FieldCountry, person.Country)).
+		Func(redact.Str(redact.FieldGender, string(person.Gender))).
 		Msg("Person retrieved successfully")
 
 	personDto := mapPersonToDto(person)
@@ -297,178 +297,329 @@ func (s *PersonService) GetPersonById(ctx context.Context, personId pgtype.UUID)
 	return personDto, nil
 }
 
-func getAge(name string) (uint32, error) {
-	ageUrl := os.Getenv("AGE_URL") + name
-	log.Debug().Str("url", ageUrl).Msg("Making request to age API")
+func (s *PersonService) EnrichPerson(ctx context.Context, personId pgtype.UUID) (*dtos.EnrichmentResultDto, error) {
+	log.Ctx(ctx).Info().
+		Str("person_id", personId.String()).
+		Msg("Re-enriching person")
 
-	resp, err := http.Get(ageUrl)
+	person, err := s.personDriver.GetPersonById(ctx, personId)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("url", ageUrl).
-			Msg(custom_errors.ErrHttpGet.Message)
-		return 0, custom_errors.ErrHttpGet
+			Str("person_id", personId.String()).
+			Msg("Person not found for re-enrichment")
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	log.Debug().Int("status_code", resp.StatusCode).Msg("Age API response received")
-	if resp.StatusCode != http.StatusOK {
-		log.Error().
-			Int("status_code", resp.StatusCode).
-			Str("url", ageUrl).
-			Msg(custom_errors.ErrGetAgeStatusCode.Message)
-		return 0, custom_errors.ErrGetAgeStatusCode
+	return s.enrichPerson(ctx, person)
+}
+
+func (s *PersonService) GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]dtos.EnrichmentProviderResultDto, error) {
+	log.Ctx(ctx).Info().
+		Str("person_id", personId.String()).
+		Msg("Getting enrichment results of person")
+
+	if _, err := s.personDriver.GetPersonById(ctx, personId); err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Str("person_id", personId.String()).
+			Msg("Person not found for enrichment results")
+		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	results, err := s.personDriver.GetEnrichmentResults(ctx, personId)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("url", ageUrl).
-			Msg(custom_errors.ErrGetAgeReadBody.Message)
-		return 0, custom_errors.ErrGetAgeReadBody
+			Str("person_id", personId.String()).
+			Msg("Failed to get enrichment results from database")
+		return nil, err
 	}
 
-	var ageDto dtos.AgeDto
-	if err = json.Unmarshal(body, &ageDto); err != nil {
-		log.Error().
-			Err(err).
-			Str("body", string(body)).
-			Msg(custom_errors.ErrGetAgeUnmarshalBody.Message)
-		return 0, custom_errors.ErrGetAgeUnmarshalBody
+	resultDtos := make([]dtos.EnrichmentProviderResultDto, 0, len(results))
+	for _, result := range results {
+		resultDtos = append(resultDtos, dtos.EnrichmentProviderResultDto{
+			Field:      result.Field,
+			Provider:   result.Provider,
+			Value:      result.Value,
+			Confidence: result.Confidence,
+			Error:      result.Error,
+			Chosen:     result.Chosen,
+			CreatedAt:  result.CreatedAt,
+		})
 	}
 
-	log.Debug().
-		Uint32("age", ageDto.Age).
-		Str("name", name).
-		Msg("Age successfully determined")
+	log.Ctx(ctx).Info().
+		Str("person_id", personId.String()).
+		Int("results_count", len(resultDtos)).
+		Msg("Enrichment results retrieved successfully")
 
-	return ageDto.Age, nil
+	return resultDtos, nil
 }
 
-func getGender(name string) (models.GenderType, error) {
-	genderUrl := os.Getenv("GENDER_URL") + name
-	log.Debug().Str("url", genderUrl).Msg("Making request to gender API")
+// EnrichPersons re-enriches at most MaxEnrichBatchSize persons matching the filters, so one
+// request cannot spend the whole provider quota or outlive the server write timeout. The rest
+// is reported as skipped and picked up by repeating the call with a larger offset.
+func (s *PersonService) EnrichPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (*dtos.EnrichmentBatchDto, error) {
+	log.Ctx(ctx).Info().Msg("Re-enriching persons with filters")
 
-	resp, err := http.Get(genderUrl)
-	if err != nil {
-		log.Error().
+	if err := validateGetPersonDto(ctx, getPersonDto); err != nil {
+		log.Ctx(ctx).Warn().
 			Err(err).
-			Str("url", genderUrl).
-			Msg(custom_errors.ErrHttpGet.Message)
-		return "", custom_errors.ErrHttpGet
+			Msg("Invalid filter parameters")
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	log.Debug().Int("status_code", resp.StatusCode).Msg("Gender API response received")
-	if resp.StatusCode != http.StatusOK {
-		log.Error().
-			Int("status_code", resp.StatusCode).
-			Str("url", genderUrl).
-			Msg(custom_errors.ErrGetGenderStatusCode.Message)
-		return "", custom_errors.ErrGetGenderStatusCode
+	if getPersonDto.Limit == nil || *getPersonDto.Limit > MaxEnrichBatchSize {
+		limit := uint32(MaxEnrichBatchSize)
+		getPersonDto.Limit = &limit
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	total, err := s.personDriver.CountPersons(ctx, getPersonDto)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("url", genderUrl).
-			Msg(custom_errors.ErrGetGenderReadBody.Message)
-		return "", custom_errors.ErrGetGenderReadBody
+			Msg("Failed to count persons for re-enrichment")
+		return nil, err
 	}
 
-	var genderDto dtos.GenderDto
-	if err = json.Unmarshal(body, &genderDto); err != nil {
-		log.Error().
+	persons, err := s.personDriver.GetPersons(ctx, getPersonDto)
+	if err != nil {
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("body", string(body)).
-			Msg(custom_errors.ErrGetGenderUnmarshalBody.Message)
-		return "", custom_errors.ErrGetGenderUnmarshalBody
+			Msg("Failed to get persons for re-enrichment")
+		return nil, err
 	}
 
-	gender := models.GenderType(genderDto.Gender)
-	log.Debug().
-		Str("gender", string(gender)).
-		Str("name", name).
-		Msg("Gender determined")
+	batch := &dtos.EnrichmentBatchDto{Results: make([]dtos.EnrichmentResultDto, 0, len(persons))}
+	failedCount := 0
+	for i := range persons {
+		result, err := s.enrichPerson(ctx, &persons[i])
+		if err != nil {
+			log.Ctx(ctx).Warn().
+				Err(err).
+				Str("person_id", persons[i].Id.String()).
+				Msg("Failed to re-enrich person, continuing with the next one")
+			result = &dtos.EnrichmentResultDto{
+				Id:            persons[i].Id,
+				Changes:       []dtos.EnrichmentChangeDto{},
+				SkippedFields: []string{},
+				Err:           err,
+			}
+			failedCount++
+		}
+		batch.Results = append(batch.Results, *result)
+	}
 
-	if gender != models.Male && gender != models.Female {
-		log.Error().
-			Str("gender", string(gender)).
-			Str("name", name).
-			Msg(custom_errors.ErrGotInvalidGender.Message)
-		return "", custom_errors.ErrGotInvalidGender
+	var offset int64
+	if getPersonDto.Offset != nil {
+		offset = int64(*getPersonDto.Offset)
 	}
+	batch.SkippedCount = max(total-offset-int64(len(persons)), 0)
 
-	log.Debug().
-		Str("gender", string(gender)).
-		Str("name", name).
-		Msg("Gender successfully validated")
+	log.Ctx(ctx).Info().
+		Int("count", len(batch.Results)).
+		Int("failed_count", failedCount).
+		Int64("skipped_count", batch.SkippedCount).
+		Msg("Persons re-enriched")
 
-	return gender, nil
+	return batch, nil
 }
 
-func getCountry(name string) (string, error) {
-	countryUrl := os.Getenv("COUNTRY_URL") + name
-	log.Debug().Str("url", countryUrl).Msg("Making request to country API")
+func (s *PersonService) PreviewEnrichment(ctx context.Context, personDto dtos.CreatePersonDto) (*dtos.EnrichmentPreviewDto, error) {
+	log.Ctx(ctx).Info().
+		Func(redact.Str(redact.FieldName, personDto.Name)).
+		Msg("Previewing person enrichment")
+
+	if strings.TrimSpace(personDto.Name) == "" {
+		log.Ctx(ctx).Warn().Msg(custom_errors.ErrEmptyName.Message)
+		return nil, custom_errors.ErrEmptyName
+	}
 
-	resp, err := http.Get(countryUrl)
+	personDto.CountryHint = normalizeCountryCode(personDto.CountryHint)
+	if err := validateCountryHint(ctx, personDto.CountryHint); err != nil {
+		return nil, err
+	}
+
+	enriched, err := s.enricher.Enrich(ctx, newEnrichmentRequest(personDto, nil))
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("url", countryUrl).
-			Msg(custom_errors.ErrHttpGet.Message)
-		return "", custom_errors.ErrHttpGet
+			Func(redact.Str(redact.FieldName, personDto.Name)).
+			Msg("Failed to enrich person attributes")
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	log.Debug().Int("status_code", resp.StatusCode).Msg("Country API response received")
-	if resp.StatusCode != http.StatusOK {
-		log.Error().
-			Int("status_code", resp.StatusCode).
-			Str("url", countryUrl).
-			Msg(custom_errors.ErrGetCountryStatusCode.Message)
-		return "", custom_errors.ErrGetCountryStatusCode
+	candidates := make([]dtos.CountryIdDto, 0, len(enriched.CountryCandidates))
+	for _, candidate := range enriched.CountryCandidates {
+		candidates = append(candidates, dtos.CountryIdDto{Id: candidate.Country, Probability: candidate.Probability})
+	}
+
+	previewDto := &dtos.EnrichmentPreviewDto{
+		Name: personDto.Name,
+		Age: dtos.AgeEstimateDto{
+			Value:    enriched.Age,
+			Count:    enriched.AgeCount,
+			Provider: enriched.AgeProvider,
+		},
+		Gender: dtos.GenderEstimateDto{
+			Value:       string(enriched.Gender),
+			Probability: enriched.GenderProbability,
+			Count:       enriched.GenderCount,
+			Provider:    enriched.GenderProvider,
+		},
+		Country: dtos.CountryEstimateDto{
+			Value:       enriched.Country,
+			Probability: enriched.CountryProbability,
+			Count:       enriched.CountryCount,
+			Provider:    enriched.CountryProvider,
+			Candidates:  candidates,
+		},
+	}
+
+	log.Ctx(ctx).Info().
+		Func(redact.Str(redact.FieldName, personDto.Name)).
+		Func(redact.Uint32(redact.FieldAge, enriched.Age)).
+		Func(redact.Str(redact.FieldGender, string(enriched.Gender))).
+		Func(redact.Str(redact.FieldCountry, enriched.Country)).
+		Msg("Person enrichment previewed")
+
+	return previewDto, nil
+}
+
+func (s *PersonService) enrichPerson(ctx context.Context, person *models.Person) (*dtos.EnrichmentResultDto, error) {
+	result := &dtos.EnrichmentResultDto{
+		Id:            person.Id,
+		Changes:       []dtos.EnrichmentChangeDto{},
+		SkippedFields: []string{},
+	}
+
+	fields := make([]string, 0)
+	for _, field := range []string{models.FieldAge, models.FieldGender, models.FieldCountry} {
+		if person.IsOverridden(field) {
+			result.SkippedFields = append(result.SkippedFields, field)
+		} else {
+			fields = append(fields, field)
+		}
+	}
+
+	if len(fields) == 0 {
+		log.Ctx(ctx).Info().
+			Str("person_id", person.Id.String()).
+			Msg("All enrichable fields are overridden, skipping re-enrichment")
+		return result, nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	request := models.EnrichmentRequest{
+		Name:       person.Name,
+		Surname:    person.Surname,
+		Patronymic: person.Patronymic,
+		Fields:     fields,
+	}
+	// A verified country localizes the age and gender estimates.
+	if person.IsOverridden(models.FieldCountry) && person.Country != "" {
+		request.CountryId = person.Country
+	}
+
+	enriched, err := s.enricher.Enrich(ctx, request)
 	if err != nil {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
-			Str("url", countryUrl).
-			Msg(custom_errors.ErrGetCountryReadBody.Message)
-		return "", custom_errors.ErrGetCountryReadBody
+			Str("person_id", person.Id.String()).
+			Msg("Failed to enrich person attributes")
+		return nil, err
 	}
 
-	var countryDto dtos.CountryDto
-	if err = json.Unmarshal(body, &countryDto); err != nil {
-		log.Error().
-			Err(err).
-			Str("body", string(body)).
-			Msg(custom_errors.ErrGetCountryUnmarshalBody.Message)
-		return "", custom_errors.ErrGetCountryUnmarshalBody
+	changes := make([]models.EnrichmentChange, 0)
+	enrichedAt := time.Now()
+
+	for _, field := range fields {
+		oldValue := fieldValue(person, field)
+		setEnrichedField(person, enriched, field, enrichedAt)
+		if newValue := fieldValue(person, field); newValue != oldValue {
+			changes = append(changes, models.EnrichmentChange{
+				Field:    field,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
 	}
 
-	log.Debug().
-		Int("countries_count", len(countryDto.Countries)).
-		Str("name", name).
-		Msg("Country candidates determined")
+	if len(changes) > 0 || len(enriched.Results) > 0 {
+		if err := s.personDriver.ApplyEnrichment(ctx, person, changes, enriched.Results); err != nil {
+			log.Ctx(ctx).Error().
+				Err(err).
+				Str("person_id", person.Id.String()).
+				Msg("Failed to apply enrichment changes")
+			return nil, err
+		}
+	}
 
-	maxProb := countryDto.Countries[0]
-	for _, country := range countryDto.Countries {
-		if country.Probability > maxProb.Probability {
-			maxProb = country
+	for _, change := range changes {
+		// ApplyEnrichment keeps fields changed manually while the providers were called.
+		if fieldValue(person, change.Field) != change.NewValue {
+			result.SkippedFields = append(result.SkippedFields, change.Field)
+			continue
 		}
+		result.Changes = append(result.Changes, dtos.EnrichmentChangeDto{
+			Field:    change.Field,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+		})
+	}
+
+	log.Ctx(ctx).Info().
+		Str("person_id", person.Id.String()).
+		Int("changes_count", len(changes)).
+		Strs("skipped_fields", result.SkippedFields).
+		Msg("Person re-enriched successfully")
+
+	return result, nil
+}
+
+func newEnrichmentRequest(personDto dtos.CreatePersonDto, fields []string) models.EnrichmentRequest {
+	request := models.EnrichmentRequest{
+		Name:    personDto.Name,
+		Surname: personDto.Surname,
+		Fields:  fields,
+	}
+	if personDto.Patronymic != nil {
+		request.Patronymic = *personDto.Patronymic
 	}
 
-	log.Debug().
-		Str("country", maxProb.Id).
-		Float64("probability", maxProb.Probability).
-		Str("name", name).
-		Msg("Country successfully determined")
+	switch {
+	case personDto.CountryHint != nil:
+		request.CountryId = *personDto.CountryHint
+	case personDto.Country != nil:
+		request.CountryId = *personDto.Country
+	}
+	return request
+}
 
-	return maxProb.Id, nil
+func setEnrichedField(person *models.Person, enriched *models.Enrichment, field string, enrichedAt time.Time) {
+	switch field {
+	case models.FieldAge:
+		person.Age = enriched.Age
+		person.AgeSource = models.AttributeSource{Source: models.EnrichedSource(enriched.AgeProvider), UpdatedAt: enrichedAt}
+	case models.FieldGender:
+		person.Gender = enriched.Gender
+		person.GenderSource = models.AttributeSource{Source: models.EnrichedSource(enriched.GenderProvider), UpdatedAt: enrichedAt}
+	case models.FieldCountry:
+		person.Country = enriched.Country
+		person.CountrySource = models.AttributeSource{Source: models.EnrichedSource(enriched.CountryProvider), UpdatedAt: enrichedAt}
+	}
+}
+
+func fieldValue(person *models.Person, field string) string {
+	switch field {
+	case models.FieldAge:
+		return strconv.FormatUint(uint64(person.Age), 10)
+	case models.FieldGender:
+		return string(person.Gender)
+	case models.FieldCountry:
+		return person.Country
+	}
+	return ""
 }
 
 func generateUuid() pgtype.UUID {
@@ -493,18 +644,74 @@ func mapPersonToDto(person *models.Person) *dtos.PersonDto {
 		Age:        &person.Age,
 		Gender:     &genderDto,
 		Country:    &person.Country,
+
+		AgeSource:     mapAttributeSourceToDto(person.AgeSource),
+		GenderSource:  mapAttributeSourceToDto(person.GenderSource),
+		CountrySource: mapAttributeSourceToDto(person.CountrySource),
 	}
 
 	return personDto
 }
 
-func validateGetPersonDto(getPersonDto dtos.GetPersonDto) error {
-	log.Debug().Msg("Validating GetPersonDto")
+func mapAttributeSourceToDto(source models.AttributeSource) *dtos.AttributeSourceDto {
+	if source.Source == "" {
+		return nil
+	}
+	return &dtos.AttributeSourceDto{Source: source.Source, UpdatedAt: source.UpdatedAt}
+}
+
+// normalizeCountryCode upper-cases a country code, so "ru" is stored as "RU".
+func normalizeCountryCode(country *string) *string {
+	if country == nil {
+		return nil
+	}
+	normalized := strings.ToUpper(strings.TrimSpace(*country))
+	return &normalized
+}
+
+// validatePersonAttributes applies the same rules to the values supplied on create and update.
+func validatePersonAttributes(ctx context.Context, age *uint32, gender *string, country *string) error {
+	if age != nil && *age > maxAge {
+		log.Ctx(ctx).Error().
+			Func(redact.Uint32(redact.FieldAge, *age)).
+			Msg(custom_errors.ErrAgeValue.Message)
+		return custom_errors.ErrAgeValue
+	}
+
+	if gender != nil && *gender != string(models.Male) && *gender != string(models.Female) {
+		log.Ctx(ctx).Error().
+			Func(redact.Str(redact.FieldGender, *gender)).
+			Msg(custom_errors.ErrInvalidGender.Message)
+		return custom_errors.ErrInvalidGender
+	}
+
+	if country != nil && !countryCodeRegexp.MatchString(*country) {
+		log.Ctx(ctx).Error().
+			Func(redact.Str(redact.FieldCountry, *country)).
+			Msg(custom_errors.ErrInvalidCountry.Message)
+		return custom_errors.ErrInvalidCountry
+	}
+
+	return nil
+}
+
+func validateCountryHint(ctx context.Context, countryHint *string) error {
+	if countryHint != nil && !countryCodeRegexp.MatchString(*countryHint) {
+		log.Ctx(ctx).Warn().
+			Func(redact.Str(redact.FieldCountry, *countryHint)).
+			Msg(custom_errors.ErrInvalidCountryHint.Message)
+		return custom_errors.ErrInvalidCountryHint
+	}
+	return nil
+}
+
+func validateGetPersonDto(ctx context.Context, getPersonDto dtos.GetPersonDto) error {
+	log.Ctx(ctx).Debug().Msg("Validating GetPersonDto")
 
 	if len(getPersonDto.Ids) > 0 {
 		for _, id := range getPersonDto.Ids {
 			if !id.Valid {
-				log.Error().
+				log.Ctx(ctx).Error().
 					Str("id", id.String()).
 					Msg(custom_errors.ErrInvalidUuid.Message)
 				return custom_errors.ErrInvalidUuid
@@ -513,39 +720,50 @@ func validateGetPersonDto(getPersonDto dtos.GetPersonDto) error {
 	}
 
 	if getPersonDto.Limit != nil && *getPersonDto.Limit < 0 {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Uint32("limit", *getPersonDto.Limit).
 			Msg(custom_errors.ErrLimitValue.Message)
 		return custom_errors.ErrLimitValue
 	}
 
 	if getPersonDto.Offset != nil && *getPersonDto.Offset < 0 {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Uint32("offset", *getPersonDto.Offset).
 			Msg(custom_errors.ErrOffsetValue.Message)
 		return custom_errors.ErrOffsetValue
 	}
 
 	if getPersonDto.LowAge != nil && *getPersonDto.LowAge < 0 {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Uint32("low_age", *getPersonDto.LowAge).
 			Msg(custom_errors.ErrLowAgeValue.Message)
 		return custom_errors.ErrLowAgeValue
 	}
 
 	if getPersonDto.HighAge != nil && *getPersonDto.HighAge < 0 {
-		log.Error().
+		log.Ctx(ctx).Error().
 			Uint32("high_age", *getPersonDto.HighAge).
 			Msg(custom_errors.ErrHighAgeValue.Message)
 		return custom_errors.ErrHighAgeValue
 	}
 
 	if getPersonDto.Gender != nil && *getPersonDto.Gender != "male" && *getPersonDto.Gender != "female" {
-		log.Error().
-			Str("gender", *getPersonDto.Gender).
+		log.Ctx(ctx).Error().
+			Func(redact.Str(redact.FieldGender, *getPersonDto.Gender)).
 			Msg(custom_errors.ErrInvalidGender.Message)
 		return custom_errors.ErrInvalidGender
 	}
 
+	for _, sources := range [][]string{getPersonDto.AgeSources, getPersonDto.GenderSources, getPersonDto.CountrySources} {
+		for _, source := range sources {
+			if !models.IsValidSource(source) {
+				log.Ctx(ctx).Error().
+					Str("source", source).
+					Msg(custom_errors.ErrInvalidSource.Message)
+				return custom_errors.ErrInvalidSource
+			}
+		}
+	}
+
 	return nil
 }
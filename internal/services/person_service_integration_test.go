@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/enrichment"
+	"effective-mobile/internal/fakeenrich"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// setupFakeEnrichment starts a fake server and returns the enricher configured with options
+// pointing at it.
+func setupFakeEnrichment(t *testing.T, fakeOptions fakeenrich.Options, options enrichment.EnricherOptions) *enrichment.Enricher {
+	dataset, err := enrichment.LoadDataset("")
+	require.NoError(t, err)
+	fakeOptions.Dataset = dataset
+
+	server := httptest.NewServer(fakeenrich.NewMux(fakeOptions))
+	t.Cleanup(server.Close)
+
+	options.Urls = map[string]string{
+		enrichment.ProviderAgify:       server.URL + "/agify/?name=",
+		enrichment.ProviderGenderize:   server.URL + "/genderize/?name=",
+		enrichment.ProviderNationalize: server.URL + "/nationalize/?name=",
+	}
+
+	enricher, err := enrichment.NewConfiguredEnricher(options)
+	require.NoError(t, err)
+	return enricher
+}
+
+func TestCreatePersonWithFakeEnrichment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CreatePerson enriches from fake APIs", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, setupFakeEnrichment(t, fakeenrich.Options{}, enrichment.EnricherOptions{}))
+
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		personDto, err := service.CreatePerson(ctx, dtos.CreatePersonDto{Name: "Anna", Surname: "Ivanova"})
+		require.NoError(t, err)
+		assert.Equal(t, uint32(44), *personDto.Age)
+		assert.Equal(t, string(models.Female), *personDto.Gender)
+		assert.Equal(t, "RU", *personDto.Country)
+		assert.Equal(t, models.EnrichedSource(enrichment.ProviderAgify), personDto.AgeSource.Source)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("CreatePerson fails on null result", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, setupFakeEnrichment(t, fakeenrich.Options{}, enrichment.EnricherOptions{}))
+
+		_, err := service.CreatePerson(ctx, dtos.CreatePersonDto{Name: "Zyxw", Surname: "Unknown"})
+		assert.Error(t, err)
+		mockDriver.AssertNotCalled(t, "CreatePerson", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreatePerson falls back to local dataset on injected errors", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		enricher := setupFakeEnrichment(t, fakeenrich.Options{ErrorRate: 1}, enrichment.EnricherOptions{
+			LocalMode: enrichment.LocalModeFallback,
+		})
+		service := NewPersonService(mockDriver, enricher)
+
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		personDto, err := service.CreatePerson(ctx, dtos.CreatePersonDto{Name: "Dmitriy", Surname: "Petrov"})
+		require.NoError(t, err)
+		assert.Equal(t, models.EnrichedSource(enrichment.ProviderLocal), personDto.AgeSource.Source)
+		assert.Equal(t, models.EnrichedSource(enrichment.ProviderLocal), personDto.GenderSource.Source)
+		assert.Equal(t, models.EnrichedSource(enrichment.ProviderLocal), personDto.CountrySource.Source)
+	})
+
+	t.Run("CreatePerson stops calling exhausted APIs", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		enricher := setupFakeEnrichment(t, fakeenrich.Options{Limit: 1}, enrichment.EnricherOptions{
+			Quota: enrichment.NewQuotaTracker(),
+		})
+		service := NewPersonService(mockDriver, enricher)
+
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		_, err := service.CreatePerson(ctx, dtos.CreatePersonDto{Name: "Ivan", Surname: "Ivanov"})
+		require.NoError(t, err)
+
+		_, err = service.CreatePerson(ctx, dtos.CreatePersonDto{Name: "Anna", Surname: "Ivanova"})
+		assert.ErrorIs(t, err, custom_errors.ErrQuotaExhausted)
+		mockDriver.AssertNumberOfCalls(t, "CreatePerson", 1)
+	})
+
+	t.Run("CreatePerson times out on slow APIs", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		enricher := setupFakeEnrichment(t, fakeenrich.Options{Latency: time.Second}, enrichment.EnricherOptions{
+			Timeout: 100 * time.Millisecond,
+		})
+		service := NewPersonService(mockDriver, enricher)
+
+		_, err := service.CreatePerson(ctx, dtos.CreatePersonDto{Name: "Ivan", Surname: "Ivanov"})
+		assert.ErrorIs(t, err, custom_errors.ErrEnrichmentTimeout)
+		mockDriver.AssertNotCalled(t, "CreatePerson", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -12,4 +12,8 @@ type PersonServiceInterface interface {
 	DeletePerson(ctx context.Context, personId pgtype.UUID) error
 	GetPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) ([]dtos.PersonDto, error)
 	GetPersonById(ctx context.Context, personId pgtype.UUID) (*dtos.PersonDto, error)
+	EnrichPerson(ctx context.Context, personId pgtype.UUID) (*dtos.EnrichmentResultDto, error)
+	EnrichPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (*dtos.EnrichmentBatchDto, error)
+	GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]dtos.EnrichmentProviderResultDto, error)
+	PreviewEnrichment(ctx context.Context, personDto dtos.CreatePersonDto) (*dtos.EnrichmentPreviewDto, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"effective-mobile/internal/drivers"
 	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/enrichment"
 	"effective-mobile/internal/models"
 	"effective-mobile/internal/models/custom_errors"
 	"github.com/google/uuid"
@@ -12,7 +13,6 @@ import (
 	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 )
 
@@ -21,8 +21,8 @@ type MockPersonDriver struct {
 	mock.Mock
 }
 
-func (m *MockPersonDriver) CreatePerson(ctx context.Context, person *models.Person) error {
-	args := m.Called(ctx, person)
+func (m *MockPersonDriver) CreatePerson(ctx context.Context, person *models.Person, results []models.ProviderResult) error {
+	args := m.Called(ctx, person, results)
 	return args.Error(0)
 }
 
@@ -34,6 +34,11 @@ func (m *MockPersonDriver) UpdatePerson(ctx context.Context, personDto dtos.Pers
 	return args.Get(0).(*models.Person), args.Error(1)
 }
 
+func (m *MockPersonDriver) ApplyEnrichment(ctx context.Context, person *models.Person, changes []models.EnrichmentChange, results []models.ProviderResult) error {
+	args := m.Called(ctx, person, changes, results)
+	return args.Error(0)
+}
+
 func (m *MockPersonDriver) DeletePerson(ctx context.Context, personId pgtype.UUID) error {
 	args := m.Called(ctx, personId)
 	return args.Error(0)
@@ -47,6 +52,19 @@ func (m *MockPersonDriver) GetPersons(ctx context.Context, getPersonDto dtos.Get
 	return args.Get(0).([]models.Person), args.Error(1)
 }
 
+func (m *MockPersonDriver) CountPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (int64, error) {
+	args := m.Called(ctx, getPersonDto)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPersonDriver) GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]models.ProviderResult, error) {
+	args := m.Called(ctx, personId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProviderResult), args.Error(1)
+}
+
 func (m *MockPersonDriver) GetPersonById(ctx context.Context, id pgtype.UUID) (*models.Person, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -55,6 +73,18 @@ func (m *MockPersonDriver) GetPersonById(ctx context.Context, id pgtype.UUID) (*
 	return args.Get(0).(*models.Person), args.Error(1)
 }
 
+type MockEnricher struct {
+	mock.Mock
+}
+
+func (m *MockEnricher) Enrich(ctx context.Context, request models.EnrichmentRequest) (*models.Enrichment, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Enrichment), args.Error(1)
+}
+
 func setupMockServer(handler http.HandlerFunc) *httptest.Server {
 	server := httptest.NewServer(handler)
 	return server
@@ -68,32 +98,31 @@ func TestCreatePerson(t *testing.T) {
 		w.Write([]byte(`{"count":3800,"name":"Dmitriy","age":44}`))
 	})
 	defer ageServer.Close()
-	os.Setenv("AGE_URL", ageServer.URL+"/?name=")
 
 	genderServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"count":34891,"name":"Dmitriy","gender":"male","probability":1.0}`))
 	})
 	defer genderServer.Close()
-	os.Setenv("GENDER_URL", genderServer.URL+"/?name=")
 
 	countryServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"count":1295,"name":"Dmitriy","country":[{"country_id":"UA","probability":0.3577828495179683},{"country_id":"RU","probability":0.1611119317856264},{"country_id":"KZ","probability":0.04676676792430468},{"country_id":"BY","probability":0.04444553279789973},{"country_id":"UZ","probability":0.018182263417322615}]}`))
 	})
 	defer countryServer.Close()
-	os.Setenv("COUNTRY_URL", countryServer.URL+"/?name=")
+
+	enricher := enrichment.NewEnricher(ageServer.URL+"/?name=", genderServer.URL+"/?name=", countryServer.URL+"/?name=")
 
 	t.Run("CreatePerson without patronymic", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enricher)
 
 		createPersonDto := dtos.CreatePersonDto{
 			Name:    "Ivan",
 			Surname: "Ivanov",
 		}
 
-		mockDriver.On("CreatePerson", mock.Anything, mock.Anything).Return(nil)
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		personDto, err := service.CreatePerson(ctx, createPersonDto)
 
@@ -104,12 +133,15 @@ func TestCreatePerson(t *testing.T) {
 		assert.NotEmpty(t, *personDto.Age)
 		assert.NotEmpty(t, *personDto.Gender)
 		assert.NotEmpty(t, *personDto.Country)
+		assert.Equal(t, models.EnrichedSource("agify"), personDto.AgeSource.Source)
+		assert.Equal(t, models.EnrichedSource("genderize"), personDto.GenderSource.Source)
+		assert.Equal(t, models.EnrichedSource("nationalize"), personDto.CountrySource.Source)
 		mockDriver.AssertExpectations(t)
 	})
 
 	t.Run("CreatePerson with patronymic", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enricher)
 
 		patronymic := "Ivanovich"
 		createPersonDto := dtos.CreatePersonDto{
@@ -118,7 +150,7 @@ func TestCreatePerson(t *testing.T) {
 			Patronymic: &patronymic,
 		}
 
-		mockDriver.On("CreatePerson", mock.Anything, mock.Anything).Return(nil)
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 		personDto, err := service.CreatePerson(ctx, createPersonDto)
 
@@ -133,12 +165,146 @@ func TestCreatePerson(t *testing.T) {
 	})
 }
 
+func TestCreatePersonWithKnownAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CreatePerson with all attributes supplied", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		var age uint32 = 30
+		gender := "female"
+		country := "KZ"
+		createPersonDto := dtos.CreatePersonDto{
+			Name:    "Anna",
+			Surname: "Ivanova",
+			Age:     &age,
+			Gender:  &gender,
+			Country: &country,
+		}
+
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		personDto, err := service.CreatePerson(ctx, createPersonDto)
+		assert.NoError(t, err)
+		assert.Equal(t, age, *personDto.Age)
+		assert.Equal(t, gender, *personDto.Gender)
+		assert.Equal(t, country, *personDto.Country)
+		assert.Equal(t, models.SourceImport, personDto.AgeSource.Source)
+		assert.Equal(t, models.SourceImport, personDto.GenderSource.Source)
+		assert.Equal(t, models.SourceImport, personDto.CountrySource.Source)
+		mockEnricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("CreatePerson enriches only missing attributes", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		gender := "male"
+		createPersonDto := dtos.CreatePersonDto{
+			Name:    "Ivan",
+			Surname: "Ivanov",
+			Gender:  &gender,
+		}
+
+		mockEnricher.On("Enrich", mock.Anything, models.EnrichmentRequest{
+			Name:    "Ivan",
+			Surname: "Ivanov",
+			Fields:  []string{models.FieldAge, models.FieldCountry},
+		}).Return(&models.Enrichment{
+			Age:             44,
+			Country:         "RU",
+			AgeProvider:     "agify",
+			CountryProvider: "nationalize",
+		}, nil)
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		personDto, err := service.CreatePerson(ctx, createPersonDto)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(44), *personDto.Age)
+		assert.Equal(t, gender, *personDto.Gender)
+		assert.Equal(t, "RU", *personDto.Country)
+		assert.Equal(t, models.EnrichedSource("agify"), personDto.AgeSource.Source)
+		assert.Equal(t, models.SourceImport, personDto.GenderSource.Source)
+		mockEnricher.AssertExpectations(t)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("CreatePerson passes country hint", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		countryHint := "KZ"
+		createPersonDto := dtos.CreatePersonDto{
+			Name:        "Ivan",
+			Surname:     "Ivanov",
+			CountryHint: &countryHint,
+		}
+
+		mockEnricher.On("Enrich", mock.Anything, models.EnrichmentRequest{
+			Name:      "Ivan",
+			Surname:   "Ivanov",
+			CountryId: countryHint,
+			Fields:    []string{models.FieldAge, models.FieldGender, models.FieldCountry},
+		}).Return(&models.Enrichment{
+			Age:     40,
+			Gender:  models.Male,
+			Country: "KZ",
+		}, nil)
+		mockDriver.On("CreatePerson", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		_, err := service.CreatePerson(ctx, createPersonDto)
+		assert.NoError(t, err)
+		mockEnricher.AssertExpectations(t)
+	})
+
+	t.Run("CreatePerson with invalid country hint", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		countryHint := "Kazakhstan"
+		personDto, err := service.CreatePerson(ctx, dtos.CreatePersonDto{
+			Name:        "Ivan",
+			Surname:     "Ivanov",
+			CountryHint: &countryHint,
+		})
+		assert.Nil(t, personDto)
+		assert.Equal(t, custom_errors.ErrInvalidCountryHint, err)
+		mockEnricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreatePerson with invalid attributes", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		country := "Russia"
+		createPersonDto := dtos.CreatePersonDto{
+			Name:    "Ivan",
+			Surname: "Ivanov",
+			Country: &country,
+		}
+
+		personDto, err := service.CreatePerson(ctx, createPersonDto)
+		assert.Error(t, err)
+		assert.Nil(t, personDto)
+		assert.Equal(t, custom_errors.ErrInvalidCountry, err)
+		mockEnricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+		mockDriver.AssertNotCalled(t, "CreatePerson", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestUpdatePerson(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("UpdatePerson with existing id", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		idBytes := uuid.New()
 		id := pgtype.UUID{Bytes: idBytes, Valid: true}
@@ -176,9 +342,48 @@ func TestUpdatePerson(t *testing.T) {
 		mockDriver.AssertExpectations(t)
 	})
 
+	t.Run("UpdatePerson upper-cases the country code", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
+
+		idBytes := uuid.New()
+		id := pgtype.UUID{Bytes: idBytes, Valid: true}
+		country := "kz"
+
+		mockDriver.On("GetPersonById", mock.Anything, id).Return(&models.Person{Id: id}, nil)
+		mockDriver.On("UpdatePerson", mock.Anything, mock.MatchedBy(func(personDto dtos.PersonDto) bool {
+			return *personDto.Country == "KZ"
+		})).Return(&models.Person{Id: id, Country: "KZ"}, nil)
+
+		personDto, err := service.UpdatePerson(ctx, dtos.PersonDto{Id: id, Country: &country})
+		assert.NoError(t, err)
+		assert.Equal(t, "KZ", *personDto.Country)
+		assert.Equal(t, "kz", country)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("UpdatePerson with invalid gender", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
+
+		idBytes := uuid.New()
+		id := pgtype.UUID{Bytes: idBytes, Valid: true}
+		gender := "unknown"
+		updatePersonDto := dtos.PersonDto{
+			Id:     id,
+			Gender: &gender,
+		}
+
+		personDto, err := service.UpdatePerson(ctx, updatePersonDto)
+		assert.Error(t, err)
+		assert.Nil(t, personDto)
+		assert.Equal(t, custom_errors.ErrInvalidGender, err)
+		mockDriver.AssertNotCalled(t, "UpdatePerson", mock.Anything, mock.Anything)
+	})
+
 	t.Run("UpdatePerson with non-existing id", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		idBytes := uuid.New()
 		id := pgtype.UUID{Bytes: idBytes, Valid: true}
@@ -205,7 +410,7 @@ func TestDeletePerson(t *testing.T) {
 
 	t.Run("DeletePerson with existing id", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		idBytes := uuid.New()
 		id := pgtype.UUID{Bytes: idBytes, Valid: true}
@@ -227,7 +432,7 @@ func TestDeletePerson(t *testing.T) {
 
 	t.Run("DeletePerson with non-existing id", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		idBytes := uuid.New()
 		id := pgtype.UUID{Bytes: idBytes, Valid: true}
@@ -246,7 +451,7 @@ func TestGetPersons(t *testing.T) {
 
 	t.Run("GetPersons without filters", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		mockDriver.On("GetPersons", mock.Anything, mock.Anything).Return([]models.Person{}, nil)
 
@@ -258,7 +463,7 @@ func TestGetPersons(t *testing.T) {
 
 	t.Run("GetPersons with valid filters", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		var lowAge uint32 = 25
 		getPersonDtos := dtos.GetPersonDto{
@@ -276,7 +481,7 @@ func TestGetPersons(t *testing.T) {
 
 	t.Run("GetPersons with invalid filters", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		gender := "non-binary"
 		getPersonDtos := dtos.GetPersonDto{
@@ -290,6 +495,21 @@ func TestGetPersons(t *testing.T) {
 		assert.Equal(t, custom_errors.ErrInvalidGender, err)
 		mockDriver.AssertExpectations(t)
 	})
+
+	t.Run("GetPersons with invalid source filter", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
+
+		getPersonDtos := dtos.GetPersonDto{
+			GenderSources: []string{"guessed"},
+		}
+
+		personDto, err := service.GetPersons(ctx, getPersonDtos)
+		assert.Error(t, err)
+		assert.Nil(t, personDto)
+		assert.Equal(t, custom_errors.ErrInvalidSource, err)
+		mockDriver.AssertExpectations(t)
+	})
 }
 
 func TestGetPersonById(t *testing.T) {
@@ -297,7 +517,7 @@ func TestGetPersonById(t *testing.T) {
 
 	t.Run("GetPersonById with existing id", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		idBytes := uuid.New()
 		id := pgtype.UUID{Bytes: idBytes, Valid: true}
@@ -312,7 +532,7 @@ func TestGetPersonById(t *testing.T) {
 
 	t.Run("GetPersonById with non-existing id", func(t *testing.T) {
 		mockDriver := new(MockPersonDriver)
-		service := NewPersonService(mockDriver)
+		service := NewPersonService(mockDriver, enrichment.NewEnricher("", "", ""))
 
 		idBytes := uuid.New()
 		id := pgtype.UUID{Bytes: idBytes, Valid: true}
@@ -326,3 +546,193 @@ func TestGetPersonById(t *testing.T) {
 		mockDriver.AssertExpectations(t)
 	})
 }
+
+func TestEnrichPerson(t *testing.T) {
+	ctx := context.Background()
+
+	idBytes := uuid.New()
+	id := pgtype.UUID{Bytes: idBytes, Valid: true}
+
+	t.Run("EnrichPerson updates changed fields", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		mockDriver.On("GetPersonById", mock.Anything, id).Return(&models.Person{
+			Id:      id,
+			Name:    "Ivan",
+			Age:     20,
+			Gender:  models.Male,
+			Country: "RU",
+
+			AgeSource:     models.AttributeSource{Source: models.EnrichedSource("agify")},
+			GenderSource:  models.AttributeSource{Source: models.EnrichedSource("genderize")},
+			CountrySource: models.AttributeSource{Source: models.EnrichedSource("nationalize")},
+		}, nil)
+		mockEnricher.On("Enrich", mock.Anything, mock.MatchedBy(func(request models.EnrichmentRequest) bool {
+			return request.Name == "Ivan"
+		})).Return(&models.Enrichment{
+			Age:     44,
+			Gender:  models.Male,
+			Country: "UA",
+
+			AgeProvider:     "agify",
+			GenderProvider:  "genderize",
+			CountryProvider: "nationalize",
+		}, nil)
+		mockDriver.On("ApplyEnrichment", mock.Anything, mock.Anything, []models.EnrichmentChange{
+			{Field: models.FieldAge, OldValue: "20", NewValue: "44"},
+			{Field: models.FieldCountry, OldValue: "RU", NewValue: "UA"},
+		}, mock.Anything).Return(nil)
+
+		result, err := service.EnrichPerson(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, result.Id)
+		assert.Len(t, result.Changes, 2)
+		assert.Empty(t, result.SkippedFields)
+		mockDriver.AssertExpectations(t)
+		mockEnricher.AssertExpectations(t)
+	})
+
+	t.Run("EnrichPerson skips manually overridden fields", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		mockDriver.On("GetPersonById", mock.Anything, id).Return(&models.Person{
+			Id:      id,
+			Name:    "Ivan",
+			Age:     20,
+			Gender:  models.Female,
+			Country: "RU",
+
+			AgeSource:     models.AttributeSource{Source: models.EnrichedSource("agify")},
+			GenderSource:  models.AttributeSource{Source: models.SourceManual},
+			CountrySource: models.AttributeSource{Source: models.SourceImport},
+		}, nil)
+		mockEnricher.On("Enrich", mock.Anything, models.EnrichmentRequest{
+			Name:      "Ivan",
+			CountryId: "RU",
+			Fields:    []string{models.FieldAge},
+		}).Return(&models.Enrichment{
+			Age:         20,
+			AgeProvider: "agify",
+		}, nil)
+
+		result, err := service.EnrichPerson(ctx, id)
+		assert.NoError(t, err)
+		assert.Empty(t, result.Changes)
+		assert.Equal(t, []string{models.FieldGender, models.FieldCountry}, result.SkippedFields)
+		mockDriver.AssertNotCalled(t, "ApplyEnrichment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockEnricher.AssertExpectations(t)
+	})
+
+	t.Run("EnrichPerson with non-existing id", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		mockDriver.On("GetPersonById", mock.Anything, id).Return(nil, custom_errors.ErrPersonNotFound)
+
+		result, err := service.EnrichPerson(ctx, id)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, custom_errors.ErrPersonNotFound, err)
+		mockEnricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+	})
+}
+
+func TestEnrichPersons(t *testing.T) {
+	ctx := context.Background()
+
+	overridden := models.Person{
+		Name:          "Ivan",
+		AgeSource:     models.AttributeSource{Source: models.SourceManual},
+		GenderSource:  models.AttributeSource{Source: models.SourceManual},
+		CountrySource: models.AttributeSource{Source: models.SourceManual},
+	}
+
+	t.Run("EnrichPersons caps the batch and reports skipped persons", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, new(MockEnricher))
+
+		var offset uint32 = 10
+		isCapped := mock.MatchedBy(func(getPersonDto dtos.GetPersonDto) bool {
+			return getPersonDto.Limit != nil && *getPersonDto.Limit == MaxEnrichBatchSize
+		})
+		mockDriver.On("CountPersons", mock.Anything, isCapped).Return(int64(250), nil)
+		mockDriver.On("GetPersons", mock.Anything, isCapped).Return([]models.Person{overridden, overridden}, nil)
+
+		limit := uint32(5000)
+		batch, err := service.EnrichPersons(ctx, dtos.GetPersonDto{Limit: &limit, Offset: &offset})
+		assert.NoError(t, err)
+		assert.Len(t, batch.Results, 2)
+		assert.Equal(t, int64(238), batch.SkippedCount)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("EnrichPersons keeps a smaller limit", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		service := NewPersonService(mockDriver, new(MockEnricher))
+
+		limit := uint32(1)
+		mockDriver.On("CountPersons", mock.Anything, mock.Anything).Return(int64(1), nil)
+		mockDriver.On("GetPersons", mock.Anything, dtos.GetPersonDto{Limit: &limit}).Return([]models.Person{overridden}, nil)
+
+		batch, err := service.EnrichPersons(ctx, dtos.GetPersonDto{Limit: &limit})
+		assert.NoError(t, err)
+		assert.Len(t, batch.Results, 1)
+		assert.Zero(t, batch.SkippedCount)
+		mockDriver.AssertExpectations(t)
+	})
+}
+
+func TestPreviewEnrichment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("PreviewEnrichment does not save person", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		mockEnricher.On("Enrich", mock.Anything, models.EnrichmentRequest{Name: "Dmitriy"}).Return(&models.Enrichment{
+			Age:     44,
+			Gender:  models.Male,
+			Country: "UA",
+
+			AgeProvider:     "agify",
+			GenderProvider:  "genderize",
+			CountryProvider: "nationalize",
+
+			AgeCount:           3800,
+			GenderProbability:  1,
+			CountryProbability: 0.36,
+			CountryCandidates: []models.CountryCandidate{
+				{Country: "UA", Probability: 0.36},
+				{Country: "RU", Probability: 0.16},
+			},
+		}, nil)
+
+		previewDto, err := service.PreviewEnrichment(ctx, dtos.CreatePersonDto{Name: "Dmitriy"})
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(44), previewDto.Age.Value)
+		assert.Equal(t, 3800, previewDto.Age.Count)
+		assert.Equal(t, "male", previewDto.Gender.Value)
+		assert.Equal(t, "UA", previewDto.Country.Value)
+		assert.Len(t, previewDto.Country.Candidates, 2)
+		mockEnricher.AssertExpectations(t)
+		mockDriver.AssertNotCalled(t, "CreatePerson", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PreviewEnrichment with empty name", func(t *testing.T) {
+		mockDriver := new(MockPersonDriver)
+		mockEnricher := new(MockEnricher)
+		service := NewPersonService(mockDriver, mockEnricher)
+
+		previewDto, err := service.PreviewEnrichment(ctx, dtos.CreatePersonDto{Name: " "})
+		assert.Error(t, err)
+		assert.Nil(t, previewDto)
+		assert.Equal(t, custom_errors.ErrEmptyName, err)
+		mockEnricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+	})
+}
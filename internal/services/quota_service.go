@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/enrichment"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+type QuotaService struct {
+	quotaTracker enrichment.QuotaTrackerInterface
+}
+
+func NewQuotaService(quotaTracker enrichment.QuotaTrackerInterface) *QuotaService {
+	log.Debug().Msg("Initializing QuotaService")
+	return &QuotaService{quotaTracker: quotaTracker}
+}
+
+func (s *QuotaService) GetQuotas(_ context.Context) []dtos.ProviderQuotaDto {
+	now := time.Now()
+	quotas := s.quotaTracker.Quotas()
+
+	quotaDtos := make([]dtos.ProviderQuotaDto, 0, len(quotas))
+	for _, quota := range quotas {
+		quotaDto := dtos.ProviderQuotaDto{
+			Provider:         quota.Provider,
+			ApiKeyConfigured: quota.ApiKeyConfigured,
+			Exhausted:        quota.IsExhausted(now),
+		}
+
+		if quota.Known {
+			limit, remaining := quota.Limit, quota.Remaining
+			resetAt, updatedAt := quota.ResetAt, quota.UpdatedAt
+			if limit > 0 {
+				quotaDto.Limit = &limit
+			}
+			quotaDto.Remaining = &remaining
+			quotaDto.ResetAt = &resetAt
+			quotaDto.UpdatedAt = &updatedAt
+		}
+
+		quotaDtos = append(quotaDtos, quotaDto)
+	}
+
+	log.Debug().
+		Int("providers_count", len(quotaDtos)).
+		Msg("Provider quotas collected")
+
+	return quotaDtos
+}
@@ -0,0 +1,10 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+)
+
+type QuotaServiceInterface interface {
+	GetQuotas(ctx context.Context) []dtos.ProviderQuotaDto
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type MockQuotaTracker struct {
+	mock.Mock
+}
+
+func (m *MockQuotaTracker) Quotas() []models.ProviderQuota {
+	args := m.Called()
+	return args.Get(0).([]models.ProviderQuota)
+}
+
+func TestGetQuotas(t *testing.T) {
+	mockTracker := new(MockQuotaTracker)
+	service := NewQuotaService(mockTracker)
+
+	resetAt := time.Now().Add(time.Hour)
+	mockTracker.On("Quotas").Return([]models.ProviderQuota{
+		{Provider: "agify"},
+		{Provider: "genderize", ApiKeyConfigured: true, Known: true, Limit: 100, Remaining: 0, ResetAt: resetAt},
+	})
+
+	quotas := service.GetQuotas(context.Background())
+	assert.Len(t, quotas, 2)
+
+	assert.Equal(t, "agify", quotas[0].Provider)
+	assert.Nil(t, quotas[0].Remaining)
+	assert.False(t, quotas[0].Exhausted)
+
+	assert.Equal(t, "genderize", quotas[1].Provider)
+	assert.Equal(t, 100, *quotas[1].Limit)
+	assert.Equal(t, 0, *quotas[1].Remaining)
+	assert.True(t, quotas[1].Exhausted)
+	assert.True(t, quotas[1].ApiKeyConfigured)
+	mockTracker.AssertExpectations(t)
+}
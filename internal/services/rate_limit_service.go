@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/drivers"
+	"effective-mobile/internal/metrics"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+type RateLimitService struct {
+	rateLimitDriver drivers.RateLimitDriverInterface
+	limits          map[string]models.RateLimit
+}
+
+// NewRateLimitService takes the limit of every policy used by the routes.
+func NewRateLimitService(rateLimitDriver drivers.RateLimitDriverInterface, limits map[string]models.RateLimit) *RateLimitService {
+	log.Debug().Int("policies_count", len(limits)).Msg("Initializing RateLimitService")
+	return &RateLimitService{rateLimitDriver: rateLimitDriver, limits: limits}
+}
+
+// Allow counts the request of the client against the policy. Each client has a separate bucket
+// per policy.
+func (s *RateLimitService) Allow(ctx context.Context, policy, client string) (*models.RateLimitResult, error) {
+	limit, ok := s.limits[policy]
+	if !ok {
+		log.Ctx(ctx).Error().
+			Str("policy", policy).
+			Msg(custom_errors.ErrUnknownRateLimitPolicy.Message)
+		return nil, custom_errors.ErrUnknownRateLimitPolicy
+	}
+
+	tokens, allowed, err := s.rateLimitDriver.TakeToken(ctx, policy+":"+client, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := limit.Result(tokens, allowed)
+	if !allowed {
+		metrics.CountRateLimited(policy)
+		log.Ctx(ctx).Warn().
+			Str("policy", policy).
+			Str("client", client).
+			Dur("retry_after", result.RetryAfter).
+			Msg(custom_errors.ErrRateLimited.Message)
+	}
+
+	return &result, nil
+}
+
+// PurgeIdle forgets the buckets that have had time to refill completely, they are the same as
+// new ones.
+func (s *RateLimitService) PurgeIdle(ctx context.Context) error {
+	var idle time.Duration
+	for _, limit := range s.limits {
+		idle = max(idle, limit.RefillTime())
+	}
+
+	deleted, err := s.rateLimitDriver.DeleteIdleBuckets(ctx, idle)
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted_count", deleted).Msg("Idle rate limit buckets purged")
+	return nil
+}
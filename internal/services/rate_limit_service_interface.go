@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+)
+
+type RateLimitServiceInterface interface {
+	Allow(ctx context.Context, policy, client string) (*models.RateLimitResult, error)
+	PurgeIdle(ctx context.Context) error
+}
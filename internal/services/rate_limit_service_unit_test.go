@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/models"
+	"effective-mobile/internal/models/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type MockRateLimitDriver struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitDriver) TakeToken(ctx context.Context, key string, limit models.RateLimit) (float64, bool, error) {
+	args := m.Called(ctx, key, limit)
+	return args.Get(0).(float64), args.Bool(1), args.Error(2)
+}
+
+func (m *MockRateLimitDriver) DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	args := m.Called(ctx, idle)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRateLimitAllow(t *testing.T) {
+	ctx := context.Background()
+	createLimit := models.RateLimit{Rate: 0.5, Burst: 10}
+	limits := map[string]models.RateLimit{
+		models.RateLimitPolicyCreate: createLimit,
+		models.RateLimitPolicyRead:   {Rate: 10, Burst: 100},
+	}
+
+	t.Run("Allowed request", func(t *testing.T) {
+		mockDriver := new(MockRateLimitDriver)
+		service := NewRateLimitService(mockDriver, limits)
+		mockDriver.On("TakeToken", ctx, "create:api_key:billing", createLimit).Return(7.5, true, nil).Once()
+
+		result, err := service.Allow(ctx, models.RateLimitPolicyCreate, "api_key:billing")
+
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 10, result.Limit)
+		assert.Equal(t, 7, result.Remaining)
+		assert.Equal(t, 5*time.Second, result.Reset)
+		assert.Zero(t, result.RetryAfter)
+		mockDriver.AssertExpectations(t)
+	})
+
+	t.Run("Rejected request", func(t *testing.T) {
+		mockDriver := new(MockRateLimitDriver)
+		service := NewRateLimitService(mockDriver, limits)
+		mockDriver.On("TakeToken", ctx, "create:ip:10.0.0.1", createLimit).Return(0.25, false, nil).Once()
+
+		result, err := service.Allow(ctx, models.RateLimitPolicyCreate, "ip:10.0.0.1")
+
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 1500*time.Millisecond, result.RetryAfter)
+	})
+
+	t.Run("Unknown policy", func(t *testing.T) {
+		_, err := NewRateLimitService(new(MockRateLimitDriver), limits).Allow(ctx, "delete", "ip:10.0.0.1")
+
+		assert.ErrorIs(t, err, custom_errors.ErrUnknownRateLimitPolicy)
+	})
+}
+
+func TestRateLimitPurgeIdle(t *testing.T) {
+	ctx := context.Background()
+	mockDriver := new(MockRateLimitDriver)
+	service := NewRateLimitService(mockDriver, map[string]models.RateLimit{
+		models.RateLimitPolicyCreate: {Rate: 0.5, Burst: 10},
+		models.RateLimitPolicyRead:   {Rate: 10, Burst: 100},
+	})
+
+	mockDriver.On("DeleteIdleBuckets", ctx, 20*time.Second).Return(int64(3), nil).Once()
+
+	assert.NoError(t, service.PurgeIdle(ctx))
+	mockDriver.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"effective-mobile/internal/dtos"
+	"effective-mobile/internal/tracing"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TracedPersonService wraps every call to the person service in a span, the driver and
+// enrichment spans of the call become its children.
+type TracedPersonService struct {
+	personService PersonServiceInterface
+}
+
+func NewTracedPersonService(personService PersonServiceInterface) *TracedPersonService {
+	log.Debug().Msg("Initializing TracedPersonService")
+	return &TracedPersonService{personService: personService}
+}
+
+func (s *TracedPersonService) CreatePerson(ctx context.Context, personDto dtos.CreatePersonDto) (*dtos.PersonDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.CreatePerson")
+	person, err := s.personService.CreatePerson(ctx, personDto)
+	tracing.End(span, err)
+	return person, err
+}
+
+func (s *TracedPersonService) UpdatePerson(ctx context.Context, personDto dtos.PersonDto) (*dtos.PersonDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.UpdatePerson", attribute.String("person.id", personDto.Id.String()))
+	person, err := s.personService.UpdatePerson(ctx, personDto)
+	tracing.End(span, err)
+	return person, err
+}
+
+func (s *TracedPersonService) DeletePerson(ctx context.Context, personId pgtype.UUID) error {
+	ctx, span := tracing.Start(ctx, "PersonService.DeletePerson", attribute.String("person.id", personId.String()))
+	err := s.personService.DeletePerson(ctx, personId)
+	tracing.End(span, err)
+	return err
+}
+
+func (s *TracedPersonService) GetPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) ([]dtos.PersonDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.GetPersons")
+	persons, err := s.personService.GetPersons(ctx, getPersonDto)
+	span.SetAttributes(attribute.Int("persons.count", len(persons)))
+	tracing.End(span, err)
+	return persons, err
+}
+
+func (s *TracedPersonService) GetPersonById(ctx context.Context, personId pgtype.UUID) (*dtos.PersonDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.GetPersonById", attribute.String("person.id", personId.String()))
+	person, err := s.personService.GetPersonById(ctx, personId)
+	tracing.End(span, err)
+	return person, err
+}
+
+func (s *TracedPersonService) EnrichPerson(ctx context.Context, personId pgtype.UUID) (*dtos.EnrichmentResultDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.EnrichPerson", attribute.String("person.id", personId.String()))
+	result, err := s.personService.EnrichPerson(ctx, personId)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s *TracedPersonService) EnrichPersons(ctx context.Context, getPersonDto dtos.GetPersonDto) (*dtos.EnrichmentBatchDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.EnrichPersons")
+	batch, err := s.personService.EnrichPersons(ctx, getPersonDto)
+	if batch != nil {
+		span.SetAttributes(
+			attribute.Int("persons.count", len(batch.Results)),
+			attribute.Int64("persons.skipped_count", batch.SkippedCount),
+		)
+	}
+	tracing.End(span, err)
+	return batch, err
+}
+
+func (s *TracedPersonService) GetEnrichmentResults(ctx context.Context, personId pgtype.UUID) ([]dtos.EnrichmentProviderResultDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.GetEnrichmentResults", attribute.String("person.id", personId.String()))
+	results, err := s.personService.GetEnrichmentResults(ctx, personId)
+	tracing.End(span, err)
+	return results, err
+}
+
+func (s *TracedPersonService) PreviewEnrichment(ctx context.Context, personDto dtos.CreatePersonDto) (*dtos.EnrichmentPreviewDto, error) {
+	ctx, span := tracing.Start(ctx, "PersonService.PreviewEnrichment")
+	preview, err := s.personService.PreviewEnrichment(ctx, personDto)
+	tracing.End(span, err)
+	return preview, err
+}
@@ -13,4 +13,8 @@ CREATE TABLE IF NOT EXISTS persons
     age INTEGER NOT NULL,
     gender gender_type NOT NULL,
     country TEXT
-);
\ No newline at end of file
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS persons;
+DROP TYPE IF EXISTS gender_type;
@@ -0,0 +1,31 @@
+-- +goose Up
+ALTER TABLE persons
+    ADD COLUMN IF NOT EXISTS age_source TEXT NOT NULL DEFAULT 'enriched:agify',
+    ADD COLUMN IF NOT EXISTS age_updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS gender_source TEXT NOT NULL DEFAULT 'enriched:genderize',
+    ADD COLUMN IF NOT EXISTS gender_updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS country_source TEXT NOT NULL DEFAULT 'enriched:nationalize',
+    ADD COLUMN IF NOT EXISTS country_updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE TABLE IF NOT EXISTS person_enrichment_changes
+(
+    id BIGSERIAL PRIMARY KEY,
+    person_id UUID NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS person_enrichment_changes_person_id_idx ON person_enrichment_changes (person_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS person_enrichment_changes;
+
+ALTER TABLE persons
+    DROP COLUMN IF EXISTS age_source,
+    DROP COLUMN IF EXISTS age_updated_at,
+    DROP COLUMN IF EXISTS gender_source,
+    DROP COLUMN IF EXISTS gender_updated_at,
+    DROP COLUMN IF EXISTS country_source,
+    DROP COLUMN IF EXISTS country_updated_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS person_enrichment_results
+(
+    id BIGSERIAL PRIMARY KEY,
+    person_id UUID NOT NULL REFERENCES persons (id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    value TEXT,
+    confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+    error TEXT,
+    chosen BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS person_enrichment_results_person_id_idx ON person_enrichment_results (person_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS person_enrichment_results;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Keys issued before roles existed had full access, they keep it.
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{admin}';
+
+ALTER TABLE api_keys
+    ALTER COLUMN roles SET DEFAULT '{reader}';
+
+-- +goose Down
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS roles;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Keys are scoped to the caller, so one principal can neither replay nor probe the keys of
+-- another. Keys stored before the change belong to no principal and simply expire.
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS principal TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+
+ALTER TABLE idempotency_keys
+    ADD PRIMARY KEY (principal, key);
+
+-- +goose Down
+-- Keys used by several principals cannot share the old primary key, only one row of each is kept.
+DELETE FROM idempotency_keys a
+    USING idempotency_keys b
+WHERE a.key = b.key
+  AND a.principal > b.principal;
+
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+
+ALTER TABLE idempotency_keys
+    ADD PRIMARY KEY (key);
+
+ALTER TABLE idempotency_keys
+    DROP COLUMN IF EXISTS principal;